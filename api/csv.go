@@ -4,11 +4,18 @@ import (
 	"encoding/csv"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/jorinvo/slangbrain/brain"
 )
 
+// Number of records written before they are flushed to the client.
+const csvFlushSize = 100
+
 // CSV returns a handler that implements GET returning a users phrases as CSV file.
+// Phrases can be filtered, sorted and paginated with the parameters of brain.ParsePhraseQuery.
+// The file is streamed while phrases are read,
+// so unlike for the JSON API there is no Link header to the next page.
 // For more see: https://slangbrain.com/api/
 func CSV(store brain.Store, errorLogger *log.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -22,29 +29,48 @@ func CSV(store brain.Store, errorLogger *log.Logger) http.Handler {
 			return
 		}
 
-		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
-
-		// Get phrases and write them as CSV to the HTTP resonse
-		csvW := csv.NewWriter(w)
-
-		phrases, err := store.GetAllPhrases(id)
+		q, err := brain.ParsePhraseQuery(r.URL.Query())
 		if err != nil {
-			errorLogger.Println(err)
-			http.Error(w, "failed reading phrases", http.StatusInternalServerError)
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		for _, p := range phrases {
-			if err := csvW.Write([]string{p.Phrase, p.Explanation}); err != nil {
-				errorLogger.Printf("failed generating CSV file for %d, at phrase '%s': %v", id, p.Phrase, err)
-				http.Error(w, "failed generating CSV file", http.StatusInternalServerError)
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+
+		// EachPhrase calls back outside of its read transactions,
+		// so slow clients don't keep a transaction open
+		csvW := csv.NewWriter(w)
+		count := 0
+		err = store.EachPhrase(id, q, func(p brain.IDPhrase) error {
+			// Alternative answers are in the third column, one per line
+			// Tags follow in optional columns, one per column so they can contain any character
+			record := []string{p.Phrase, p.Explanation}
+			if len(p.Alternatives) > 0 || len(p.Tags) > 0 {
+				record = append(append(record, strings.Join(p.Alternatives, "\n")), p.Tags...)
 			}
+			if err := csvW.Write(record); err != nil {
+				return err
+			}
+			count++
+			if count%csvFlushSize == 0 {
+				csvW.Flush()
+			}
+			return csvW.Error()
+		})
+		if err == nil {
+			csvW.Flush()
+			err = csvW.Error()
 		}
-
-		csvW.Flush()
-		if err := csvW.Error(); err != nil {
+		// Errors can only be sent before the file is written
+		if err == brain.ErrInvalidCursor {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
 			errorLogger.Printf("failed generating CSV file for %d: %v", id, err)
-			http.Error(w, "failed generating CSV file", http.StatusInternalServerError)
+			if count == 0 {
+				http.Error(w, "failed generating CSV file", http.StatusInternalServerError)
+			}
 		}
 	})
 }
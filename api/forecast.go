@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+// Forecast returns a handler that implements GET for /?token=:token&days=:days
+// to read the expected study load of a user for the next days.
+// Days defaults to brain.ForecastDays.
+// For more see: https://slangbrain.com/api/
+func Forecast(store brain.Store, errorLogger *log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		id, ok := getID(store, errorLogger, w, r, true)
+		if !ok {
+			return
+		}
+
+		if r.Method != "GET" {
+			jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		days := brain.ForecastDays
+		if d := r.URL.Query().Get("days"); d != "" {
+			var err error
+			if days, err = strconv.Atoi(d); err != nil || days < 1 || days > brain.MaxForecastDays {
+				jsonError(w, fmt.Sprintf("days must be between 1 and %d", brain.MaxForecastDays), http.StatusBadRequest)
+				return
+			}
+		}
+
+		forecast, err := store.Forecast(id, days)
+		if err != nil {
+			errorLogger.Println(err)
+			jsonError(w, "failed reading forecast", http.StatusInternalServerError)
+			return
+		}
+
+		data := struct {
+			Data []brain.ForecastDay `json:"data"`
+		}{forecast}
+		e := json.NewEncoder(w)
+		e.SetIndent("", "  ")
+		if err := e.Encode(data); err != nil {
+			errorLogger.Printf("failed generating JSON for %d: %v", id, err)
+			jsonError(w, "failed generating JSON", http.StatusInternalServerError)
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/jorinvo/slangbrain/brain"
 )
@@ -30,3 +31,19 @@ func jsonError(w http.ResponseWriter, msg string, code int) {
 	w.WriteHeader(code)
 	fmt.Fprintln(w, `{ "error": "`+msg+`" }`)
 }
+
+// Set a Link header to the next page of a query if there is one.
+// The link is built from the original request URI to keep the path handlers are mounted at.
+func setNextLink(w http.ResponseWriter, r *http.Request, next string) {
+	if next == "" {
+		return
+	}
+	u, err := url.Parse(r.RequestURI)
+	if err != nil || r.RequestURI == "" {
+		u = r.URL
+	}
+	v := u.Query()
+	v.Set("cursor", next)
+	link := url.URL{Path: u.Path, RawQuery: v.Encode()}
+	w.Header().Set("Link", "<"+link.String()+`>; rel="next"`)
+}
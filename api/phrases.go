@@ -6,11 +6,15 @@ import (
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/jorinvo/slangbrain/brain"
 )
 
 // Phrases returns a handler that implements GET and POST for / and DELETE and PUT for /:phraseid?token=:token
+// GET for /:phraseid/history returns all reviews of a phrase and statistics about them.
+// GET can be filtered, sorted and paginated with the parameters of brain.ParsePhraseQuery.
+// If there are more phrases, a Link header points to the next page.
 // For more see: https://slangbrain.com/api/
 func Phrases(store brain.Store, errorLogger *log.Logger) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -40,16 +44,27 @@ func handlePhrases(store brain.Store, errorLogger *log.Logger, w http.ResponseWr
 
 	switch r.Method {
 	case "GET":
-		phrases, err := store.GetAllPhrases(id)
+		q, err := brain.ParsePhraseQuery(r.URL.Query())
+		if err != nil {
+			jsonError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		phrases, next, err := store.QueryPhrases(id, q)
+		if err == brain.ErrInvalidCursor {
+			jsonError(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
 		if err != nil {
 			errorLogger.Println(err)
 			jsonError(w, "failed reading phrases", http.StatusInternalServerError)
 			return
 		}
 
+		setNextLink(w, r, next)
 		data := struct {
 			Data []brain.IDPhrase `json:"data"`
-		}{phrases}
+			Next string           `json:"next,omitempty"`
+		}{phrases, next}
 
 		e := json.NewEncoder(w)
 		e.SetIndent("", "  ")
@@ -74,7 +89,7 @@ func handlePhrases(store brain.Store, errorLogger *log.Logger, w http.ResponseWr
 			return
 		}
 
-		fmt.Fprintf(w, `{ "status": "ok", "count": "`+strconv.Itoa(count)+`" }`)
+		fmt.Fprint(w, `{ "status": "ok", "count": "`+strconv.Itoa(count)+`" }`)
 
 	default:
 		jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
@@ -82,7 +97,8 @@ func handlePhrases(store brain.Store, errorLogger *log.Logger, w http.ResponseWr
 }
 
 func handlePhrase(store brain.Store, errorLogger *log.Logger, w http.ResponseWriter, r *http.Request) {
-	seq, err := strconv.Atoi(r.URL.Path)
+	parts := strings.SplitN(r.URL.Path, "/", 2)
+	seq, err := strconv.Atoi(parts[0])
 	if err != nil {
 		errorLogger.Printf("invalid phrase id '%s': %v", r.URL.Path, err)
 		jsonError(w, "invalid phrase id", http.StatusBadRequest)
@@ -94,16 +110,62 @@ func handlePhrase(store brain.Store, errorLogger *log.Logger, w http.ResponseWri
 		return
 	}
 
+	if len(parts) > 1 {
+		if parts[1] != "history" {
+			jsonError(w, "not found", http.StatusNotFound)
+			return
+		}
+		handleHistory(store, errorLogger, w, r, id, seq)
+		return
+	}
+
 	switch r.Method {
 	case "PUT":
+		// Alternatives, direction, tags and suspended are optional to keep them unchanged if not passed
 		var data struct {
-			Data brain.Phrase `json:"data"`
+			Data struct {
+				Phrase       string           `json:"phrase"`
+				Explanation  string           `json:"explanation"`
+				Alternatives *[]string        `json:"alternatives"`
+				Direction    *brain.Direction `json:"direction"`
+				Tags         *[]string        `json:"tags"`
+				Suspended    *bool            `json:"suspended"`
+			} `json:"data"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
 			jsonError(w, "failed to parse body", http.StatusBadRequest)
 			return
 		}
-		if err := store.UpdatePhrase(id, seq, data.Data.Phrase, data.Data.Explanation); err != nil {
+		// Apply all changes at once, so a failed change doesn't leave the phrase half updated
+		err := store.Update(func(s brain.Store) error {
+			if err := s.UpdatePhrase(id, seq, data.Data.Phrase, data.Data.Explanation); err != nil {
+				return err
+			}
+			if data.Data.Alternatives != nil {
+				if err := s.SetAlternatives(id, seq, *data.Data.Alternatives); err != nil {
+					return err
+				}
+			}
+			if data.Data.Direction != nil {
+				if err := s.SetDirection(id, seq, *data.Data.Direction); err != nil {
+					return err
+				}
+			}
+			if data.Data.Tags != nil {
+				if err := s.SetTags(id, seq, *data.Data.Tags); err != nil {
+					return err
+				}
+			}
+			if data.Data.Suspended != nil {
+				return s.SuspendPhrase(id, seq, *data.Data.Suspended)
+			}
+			return nil
+		})
+		if err != nil {
+			if err == brain.ErrNotFound {
+				jsonError(w, "phrase does not exist", http.StatusNotFound)
+				return
+			}
 			errorLogger.Printf("failed to update phrase: %v", err)
 			jsonError(w, "failed to update phrase", http.StatusInternalServerError)
 			return
@@ -128,3 +190,42 @@ func handlePhrase(store brain.Store, errorLogger *log.Logger, w http.ResponseWri
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	fmt.Fprintln(w, `{ "status": "ok" }`)
 }
+
+func handleHistory(store brain.Store, errorLogger *log.Logger, w http.ResponseWriter, r *http.Request, id int64, seq int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	if r.Method != "GET" {
+		jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reviews, stats, err := store.GetHistory(id, seq)
+	if err == brain.ErrNotFound {
+		jsonError(w, "phrase does not exist", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		errorLogger.Println(err)
+		jsonError(w, "failed reading history", http.StatusInternalServerError)
+		return
+	}
+
+	var data struct {
+		Data struct {
+			Reviews []brain.Review    `json:"reviews"`
+			Stats   brain.PhraseStats `json:"stats"`
+		} `json:"data"`
+	}
+	data.Data.Reviews = reviews
+	data.Data.Stats = stats
+	if data.Data.Reviews == nil {
+		data.Data.Reviews = []brain.Review{}
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(data); err != nil {
+		errorLogger.Printf("failed generating JSON for %d: %v", id, err)
+		jsonError(w, "failed generating JSON", http.StatusInternalServerError)
+	}
+}
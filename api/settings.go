@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+// Settings returns a handler that implements GET and PUT for /?token=:token
+// to read and change the study settings of a user.
+// For more see: https://slangbrain.com/api/
+func Settings(store brain.Store, errorLogger *log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				errorLogger.Printf("method=%s; path=%s] failed closing body: %v", r.Method, r.URL.Path, err)
+			}
+		}()
+
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		id, ok := getID(store, errorLogger, w, r, true)
+		if !ok {
+			return
+		}
+
+		switch r.Method {
+		case "GET":
+			s, err := store.GetSettings(id)
+			if err != nil {
+				errorLogger.Println(err)
+				jsonError(w, "failed reading settings", http.StatusInternalServerError)
+				return
+			}
+
+			data := struct {
+				Data brain.Settings `json:"data"`
+			}{s}
+			e := json.NewEncoder(w)
+			e.SetIndent("", "  ")
+			if err := e.Encode(data); err != nil {
+				errorLogger.Printf("failed generating JSON for %d: %v", id, err)
+				jsonError(w, "failed generating JSON", http.StatusInternalServerError)
+			}
+
+		case "PUT":
+			// Start with the current settings to keep settings that are not passed
+			s, err := store.GetSettings(id)
+			if err != nil {
+				errorLogger.Println(err)
+				jsonError(w, "failed reading settings", http.StatusInternalServerError)
+				return
+			}
+			data := struct {
+				Data *brain.Settings `json:"data"`
+			}{&s}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				jsonError(w, "failed to parse body", http.StatusBadRequest)
+				return
+			}
+			if err := s.Validate(); err != nil {
+				jsonError(w, fmt.Sprintf("invalid settings: %v", err), http.StatusBadRequest)
+				return
+			}
+			if err := store.SetSettings(id, s); err != nil {
+				errorLogger.Printf("failed to update settings: %v", err)
+				jsonError(w, "failed to update settings", http.StatusInternalServerError)
+				return
+			}
+			fmt.Fprintln(w, `{ "status": "ok" }`)
+
+		default:
+			jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
+		}
+	})
+}
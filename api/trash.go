@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+// Trash returns a handler that implements GET for /?token=:token
+// to read the deleted phrases of a user
+// and POST for /:phraseid/restore?token=:token to restore a deleted phrase.
+// Deleted phrases are purged after a retention period.
+// For more see: https://slangbrain.com/api/
+func Trash(store brain.Store, errorLogger *log.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		id, ok := getID(store, errorLogger, w, r, true)
+		if !ok {
+			return
+		}
+
+		if r.URL.Path == "" {
+			handleTrash(store, errorLogger, w, r, id)
+			return
+		}
+
+		parts := strings.SplitN(r.URL.Path, "/", 2)
+		seq, err := strconv.Atoi(parts[0])
+		if err != nil {
+			errorLogger.Printf("invalid phrase id '%s': %v", r.URL.Path, err)
+			jsonError(w, "invalid phrase id", http.StatusBadRequest)
+			return
+		}
+		if len(parts) < 2 || parts[1] != "restore" {
+			jsonError(w, "not found", http.StatusNotFound)
+			return
+		}
+		if r.Method != "POST" {
+			jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := store.RestorePhrase(id, seq); err != nil {
+			if err == brain.ErrNotFound {
+				jsonError(w, "phrase is not in trash", http.StatusNotFound)
+				return
+			}
+			errorLogger.Printf("failed to restore phrase: %v", err)
+			jsonError(w, "failed to restore phrase", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintln(w, `{ "status": "ok" }`)
+	})
+}
+
+func handleTrash(store brain.Store, errorLogger *log.Logger, w http.ResponseWriter, r *http.Request, id int64) {
+	if r.Method != "GET" {
+		jsonError(w, "unsupported method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	phrases, err := store.GetTrash(id)
+	if err != nil {
+		errorLogger.Println(err)
+		jsonError(w, "failed reading trash", http.StatusInternalServerError)
+		return
+	}
+	if phrases == nil {
+		phrases = []brain.TrashedPhrase{}
+	}
+
+	data := struct {
+		Data []brain.TrashedPhrase `json:"data"`
+	}{phrases}
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	if err := e.Encode(data); err != nil {
+		errorLogger.Printf("failed generating JSON for %d: %v", id, err)
+		jsonError(w, "failed generating JSON", http.StatusInternalServerError)
+	}
+}
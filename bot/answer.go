@@ -0,0 +1,241 @@
+package bot
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+// Outcome of comparing an answer to the phrase a user studies.
+type answer int
+
+const (
+	answerWrong answer = iota
+	// Answer has a few typos or is missing diacritics
+	answerClose
+	answerExact
+)
+
+// Letters with diacritics mapped to their base letters.
+// Covers the Latin-1 Supplement and Latin Extended-A blocks.
+var diacritics = map[rune]rune{}
+
+func init() {
+	for base, letters := range map[rune]string{
+		'a': "àáâãäåāăą",
+		'c': "çćĉċč",
+		'd': "ď",
+		'e': "èéêëēĕėęě",
+		'g': "ĝğġģ",
+		'h': "ĥ",
+		'i': "ìíîïĩīĭįı",
+		'j': "ĵ",
+		'k': "ķ",
+		'l': "ĺļľŀł",
+		'n': "ñńņňŉ",
+		'o': "òóôõöøōŏő",
+		'r': "ŕŗř",
+		's': "śŝşš",
+		't': "ţťŧ",
+		'u': "ùúûüũūŭůűų",
+		'w': "ŵ",
+		'y': "ýÿŷ",
+		'z': "źżž",
+	} {
+		for _, r := range letters {
+			diacritics[r] = base
+		}
+	}
+}
+
+// Check an answer against the phrase of a study and its alternatives.
+// Returns the best result and the answer that matched closest.
+func checkAnswers(msg string, study brain.Study) (answer, string) {
+	result, closest := answerWrong, study.Phrase
+	for _, phrase := range append([]string{study.Phrase}, study.Alternatives...) {
+		if r := checkAnswer(msg, phrase); r > result {
+			result, closest = r, phrase
+		}
+	}
+	return result, closest
+}
+
+// Join the phrase of a study and its alternatives to show them to the user.
+func formatAnswers(study brain.Study) string {
+	return strings.Join(append([]string{study.Phrase}, study.Alternatives...), " / ")
+}
+
+// Reveal a phrase step by step.
+// The first hint only shows the number of words and letters,
+// every following hint reveals one more letter of each word.
+func hint(phrase string, hints int) string {
+	words := strings.Fields(phrase)
+	for i, w := range words {
+		letters := []rune(w)
+		for j := hints - 1; j < len(letters); j++ {
+			if unicode.IsLetter(letters[j]) || unicode.IsDigit(letters[j]) {
+				letters[j] = '_'
+			}
+		}
+		words[i] = string(letters)
+	}
+	return strings.Join(words, " ")
+}
+
+// Check an answer against a phrase.
+// Answers are exact if they match one of the normalized forms of the phrase.
+// Answers are close if they only differ in diacritics
+// or if they have less typos than allowed for the length of the phrase.
+func checkAnswer(msg, phrase string) answer {
+	msgA, msgB := normPhrases(msg)
+	phraseA, phraseB := normPhrases(phrase)
+	if msgA == phraseA || msgB == phraseB {
+		return answerExact
+	}
+
+	for _, forms := range [][2]string{{msgA, phraseA}, {msgB, phraseB}} {
+		m, p := stripDiacritics(forms[0]), stripDiacritics(forms[1])
+		if m == "" || p == "" {
+			continue
+		}
+		if distance([]rune(m), []rune(p), runeEqual) <= maxTypos(utf8.RuneCountInString(p)) {
+			return answerClose
+		}
+	}
+	return answerWrong
+}
+
+// Number of typos allowed for a phrase with n letters.
+// Short phrases need to be exact, otherwise a typo might result in a different word.
+func maxTypos(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 8:
+		return 1
+	case n < 14:
+		return 2
+	default:
+		return n / 6
+	}
+}
+
+// Replace letters with diacritics by their base letters
+// and remove combining marks.
+// Expects lower case input.
+func stripDiacritics(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		if base, ok := diacritics[r]; ok {
+			return base
+		}
+		return r
+	}, s)
+}
+
+func runeEqual(a, b rune) bool {
+	return a == b
+}
+
+func runeEqualFold(a, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// Levenshtein distance between a and b.
+func distance(a, b []rune, equal func(rune, rune) bool) int {
+	d := editMatrix(a, b, equal)
+	return d[len(a)][len(b)]
+}
+
+// Matrix of edit distances between all prefixes of a and b.
+func editMatrix(a, b []rune, equal func(rune, rune) bool) [][]int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if equal(a[i-1], b[j-1]) {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+		}
+	}
+	return d
+}
+
+// Show how to get from an answer to the phrase
+// using Messenger formatting.
+// Letters only in the answer are ~striked through~,
+// letters only in the phrase are *bold*.
+// Case is ignored.
+func diffAnswer(msg, phrase string) string {
+	a, b := []rune(strings.TrimSpace(msg)), []rune(strings.TrimSpace(phrase))
+	d := editMatrix(a, b, runeEqualFold)
+
+	// Walk back from the end to find the edits
+	var ops []byte
+	var chars []rune
+	i, j := len(a), len(b)
+	for i > 0 || j > 0 {
+		switch {
+		case i > 0 && j > 0 && runeEqualFold(a[i-1], b[j-1]) && d[i][j] == d[i-1][j-1]:
+			ops, chars = append(ops, '='), append(chars, b[j-1])
+			i, j = i-1, j-1
+		case j > 0 && (i == 0 || d[i][j] == d[i][j-1]+1):
+			ops, chars = append(ops, '+'), append(chars, b[j-1])
+			j--
+		case i > 0 && j > 0 && d[i][j] == d[i-1][j-1]+1:
+			// Substitution, delete first, since the ops are reversed
+			ops, chars = append(ops, '+', '-'), append(chars, b[j-1], a[i-1])
+			i, j = i-1, j-1
+		default:
+			ops, chars = append(ops, '-'), append(chars, a[i-1])
+			i--
+		}
+	}
+
+	// Group consecutive edits of the same kind
+	var s strings.Builder
+	var group []rune
+	var op byte
+	flush := func() {
+		switch op {
+		case '+':
+			s.WriteString("*" + string(group) + "*")
+		case '-':
+			s.WriteString("~" + string(group) + "~")
+		default:
+			s.WriteString(string(group))
+		}
+		group = group[:0]
+	}
+	for k := len(ops) - 1; k >= 0; k-- {
+		if ops[k] != op {
+			flush()
+			op = ops[k]
+		}
+		group = append(group, chars[k])
+	}
+	flush()
+	return s.String()
+}
+
+func min(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
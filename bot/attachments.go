@@ -37,12 +37,12 @@ func (b bot) handleAttachments(u scope.User, attachments []fbot.Attachment) {
 
 		// Notify admin for non-file and non-fallback attachments
 		if a.Type != "file" {
-			b.feedback <- Feedback{
+			b.sendFeedback(Feedback{
 				ChatID:   u.ID,
 				Username: u.Name(),
 				Message:  fmt.Sprintf("[ user sent unhandled '%s' (sticker %d): %s ]", a.Type, a.Sticker, a.URL),
 				Channel:  slackUnhandled,
-			}
+			})
 
 			continue
 		}
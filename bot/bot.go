@@ -8,10 +8,12 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/jorinvo/slangbrain/brain"
 	"github.com/jorinvo/slangbrain/payload"
+	"github.com/jorinvo/slangbrain/scope"
 	"github.com/jorinvo/slangbrain/translate"
 	"qvl.io/fbot"
 )
@@ -39,10 +41,17 @@ type bot struct {
 	client       fbot.Client
 	feedback     chan<- Feedback
 	notifyTimers map[int64]*time.Timer
+	goalTimers   map[int64]*time.Timer
+	timersMu     *sync.Mutex
 	messageDelay time.Duration
+	eventTx      bool
+	out          *outbox
 	furl         string
 }
 
+// outbox holds the messages of an event until its transaction is committed.
+type outbox []func() error
+
 // Config to pass to new for creating a Bot.
 type Config struct {
 	Store        brain.Store          // Required.
@@ -56,6 +65,7 @@ type Config struct {
 	Translator   translate.Translator // Optional. Set the translator service to enable linking.
 	FacebookURL  string               // Optional. Overwrite the default URL of the Facebook API.
 	MessageDelay time.Duration        // Optional. Time to wait between sending messages when sending multiple in a row.
+	EventTx      bool                 // Handles each event in a single database transaction. Saves disk syncs, but events are handled one at a time.
 	Setup        bool
 	Doer         func(req *http.Request) (*http.Response, error) // Optional. Pass http.Client.Do. Default is http.DefaultClient.
 }
@@ -103,9 +113,10 @@ func New(c Config) (http.Handler, func(id int64, msg string) error, error) {
 	}
 
 	// Init map because scheduleNotify will check if it is initialized.
-	var notifyTimers map[int64]*time.Timer
+	var notifyTimers, goalTimers map[int64]*time.Timer
 	if c.Notify {
 		notifyTimers = map[int64]*time.Timer{}
+		goalTimers = map[int64]*time.Timer{}
 	}
 
 	b := bot{
@@ -117,7 +128,10 @@ func New(c Config) (http.Handler, func(id int64, msg string) error, error) {
 		feedback:     feedback,
 		client:       fbot.New(fbot.Config{Token: c.Token, Secret: c.Secret, API: c.FacebookURL}),
 		notifyTimers: notifyTimers,
+		goalTimers:   goalTimers,
+		timersMu:     &sync.Mutex{},
 		messageDelay: c.MessageDelay,
+		eventTx:      c.EventTx,
 	}
 	h := b.client.Webhook(b.handleEvent, c.Secret, c.VerifyToken)
 
@@ -166,7 +180,46 @@ func (b bot) SendMessage(id int64, msg string) error {
 }
 
 // handleEvent handles a Messenger event.
+// With EventTx all store calls of the event share one transaction.
+// Messages are only sent after the transaction is committed,
+// so the database isn't locked while talking to Facebook.
+// Events that download files are handled without a shared transaction.
 func (b bot) handleEvent(e fbot.Event) {
+	if !b.eventTx || e.Type == fbot.EventError || hasDownloads(e) {
+		b.handle(e)
+		return
+	}
+	// Fetch the profile before locking the database
+	var u scope.User
+	if e.Type != fbot.EventRead {
+		u = b.getUser(e.ChatID)
+	}
+	out := outbox{}
+	b.out = &out
+	err := b.store.Update(func(s brain.Store) error {
+		b.store = s
+		b.handle(e)
+		return nil
+	})
+	b.out = nil
+	if err != nil {
+		b.err.Printf("failed to commit event for %d: %v\n", e.ChatID, err)
+		if e.Type != fbot.EventRead {
+			b.send(u.ID, u.Msg.Error, nil, nil)
+		}
+		return
+	}
+	for _, send := range out {
+		b.deliver(send)
+	}
+}
+
+// Referrals, links and attachments can point to files that are downloaded to import phrases.
+func hasDownloads(e fbot.Event) bool {
+	return e.Type == fbot.EventReferral || e.Ref != "" || getLinks(e.Text) != nil || len(e.Attachments) > 0
+}
+
+func (b bot) handle(e fbot.Event) {
 	if e.Type == fbot.EventError {
 		b.err.Println("webhook error:", e.Text)
 		return
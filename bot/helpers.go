@@ -44,9 +44,12 @@ func (b bot) messageStartMenu(u scope.User) (int64, string, []fbot.Reply, error)
 			phrases += u.Msg.Phrases
 		}
 		msg := fmt.Sprintf(u.Msg.WeeklyStats, phrases, s.Studied, s.Score, s.Rank)
+		if s.Streak > 0 {
+			msg += "\n\n" + formatStreak(u.Msg, s.Streak)
+		}
 		b.send(u.ID, msg, nil, nil)
 	} else if err != brain.ErrNotReady {
-		b.err.Println("failed to get user stats for %d: %v", u.ID, err)
+		b.err.Printf("failed to get user stats for %d: %v", u.ID, err)
 	}
 
 	return u.ID, u.Msg.Menu, u.Rpl.MenuMode, nil
@@ -59,7 +62,7 @@ func (b bot) messageWelcome(u scope.User, referral string) {
 	}
 
 	b.send(u.ID, fmt.Sprintf(u.Msg.Welcome1, u.Name()), nil, nil)
-	time.Sleep(b.messageDelay)
+	b.wait()
 
 	if b.startWithReferral(u, referral) {
 		return
@@ -67,9 +70,9 @@ func (b bot) messageWelcome(u scope.User, referral string) {
 
 	// Start by adding phrases
 	b.send(u.ID, u.Msg.Welcome2, nil, nil)
-	time.Sleep(b.messageDelay)
+	b.wait()
 	b.send(u.ID, u.Msg.Welcome3, nil, nil)
-	time.Sleep(b.messageDelay)
+	b.wait()
 	b.send(u.ID, u.Msg.Welcome4, nil, b.store.SetMode(u.ID, brain.ModeAdd))
 }
 
@@ -105,7 +108,7 @@ func (b bot) startWithReferral(u scope.User, referral string) bool {
 	}
 
 	b.send(u.ID, fmt.Sprintf(u.Msg.WelcomeReferral, count, files), nil, nil)
-	time.Sleep(b.messageDelay)
+	b.wait()
 
 	if err := b.store.SetMode(u.ID, brain.ModeStudy); err != nil {
 		b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
@@ -118,11 +121,39 @@ func (b bot) startWithReferral(u scope.User, referral string) bool {
 // Change to study mode and find correct message.
 // Return values can be passed directly to b.send().
 func (b bot) startStudy(u scope.User) (int64, string, []fbot.Reply, error) {
-	study, err := b.store.GetStudy(u.ID)
+	return b.nextStudy(u, false)
+}
+
+// Like startStudy, but if undo is set the user can undo the previous study.
+// When there are no more studies after a study, the session is summarized.
+// Users with daily goals see their progress after each study.
+// Users who study multiple choice get options to choose from instead of the show replies.
+func (b bot) nextStudy(u scope.User, undo bool) (int64, string, []fbot.Reply, error) {
+	settings, err := b.store.GetSettings(u.ID)
+	if err != nil {
+		b.err.Println(err)
+	}
+	var study brain.Study
+	var options []string
+	if settings.MultipleChoice {
+		study, options, err = b.store.GetChoices(u.ID)
+	} else {
+		study, err = b.store.GetStudy(u.ID)
+	}
 	if err != nil {
 		return u.ID, u.Msg.Error, u.Rpl.StudyMode, err
 	}
 
+	// Show progress of the daily goals after each study
+	progress := ""
+	if undo && (settings.DailyStudies > 0 || settings.DailyAdds > 0) {
+		if p, err := b.store.GetProgress(u.ID, u.Timezone()); err != nil {
+			b.err.Println(err)
+		} else {
+			progress = formatProgress(u.Msg, p) + "\n\n"
+		}
+	}
+
 	// No studies ready
 	if study.Total == 0 {
 		// Go to menu mode
@@ -136,7 +167,14 @@ func (b bot) startStudy(u scope.User) (int64, string, []fbot.Reply, error) {
 		}
 
 		// Display time until next study is ready
-		msg := fmt.Sprintf(u.Msg.StudyDone, formatDuration(u.Msg, study.Next))
+		msg := progress + fmt.Sprintf(u.Msg.StudyDone, formatDuration(u.Msg, study.Next))
+		if undo {
+			if s, err := b.store.GetSummary(u.ID); err != nil {
+				b.err.Println(err)
+			} else if s.Studied > 0 {
+				msg += "\n\n" + formatSummary(u.Msg, s)
+			}
+		}
 		isSubscribed, err := b.store.IsSubscribed(u.ID)
 		if err != nil {
 			b.err.Println(err)
@@ -150,17 +188,48 @@ func (b bot) startStudy(u scope.User) (int64, string, []fbot.Reply, error) {
 	}
 
 	// Send study to user
-	return u.ID, fmt.Sprintf(u.Msg.StudyQuestion, study.Total, study.Explanation), u.Rpl.Show, nil
+	show := u.Rpl.Show
+	if undo {
+		show = u.Rpl.ShowUndo
+	}
+	// Multiple choice needs at least one wrong option
+	if len(options) > 1 {
+		show = u.Rpl.Choices(study.Card, options, undo)
+	}
+	question := u.Msg.StudyQuestion
+	if study.Cloze {
+		question = u.Msg.StudyQuestionCloze
+	} else if study.Reverse {
+		question = u.Msg.StudyQuestionReverse
+	}
+	return u.ID, progress + fmt.Sprintf(question, study.Total, study.Explanation), show, nil
 }
 
 // Score current study and continue with next one.
-// Return values can be passed directly to b.send().
-func (b bot) scoreAndStudy(u scope.User, score int) (int64, string, []fbot.Reply, error) {
-	err := b.store.ScoreStudy(u.ID, score)
+// The user can undo the score from the next study.
+// If the phrase became a leech, the user is asked what to do with it instead.
+func (b bot) scoreAndStudy(u scope.User, grade brain.Grade) {
+	leech, err := b.store.ScoreStudy(u.ID, grade)
 	if err != nil {
-		return u.ID, u.Msg.Error, u.Rpl.StudyMode, err
+		b.send(u.ID, u.Msg.Error, u.Rpl.StudyMode, err)
+		return
+	}
+	if leech == 0 {
+		b.send(b.nextStudy(u, true))
+		return
+	}
+
+	// Link to the phrase manager to edit the phrase
+	token, err := b.store.GenerateToken(u.ID)
+	if err != nil {
+		b.err.Println(err)
+	}
+	buttons := u.Btn.Leech(token)
+	if buttons == nil {
+		b.send(u.ID, u.Msg.Leech, u.Rpl.Leech(leech), nil)
+		return
 	}
-	return b.startStudy(u)
+	b.sendWithButtons(u.ID, u.Msg.Leech, u.Rpl.Leech(leech), buttons)
 }
 
 // Send replies and log errors.
@@ -168,7 +237,41 @@ func (b bot) send(id int64, reply string, buttons []fbot.Reply, err error) {
 	if err != nil {
 		b.err.Println(err)
 	}
-	if err = b.client.Send(id, reply, buttons); err != nil {
+	b.deliver(func() error {
+		return b.client.Send(id, reply, buttons)
+	})
+}
+
+// Send replies with buttons and log errors.
+func (b bot) sendWithButtons(id int64, reply string, replies []fbot.Reply, buttons []fbot.Button) {
+	b.deliver(func() error {
+		return b.client.SendWithButtons(id, reply, replies, buttons)
+	})
+}
+
+// Wait before sending the next message.
+func (b bot) wait() {
+	b.deliver(func() error {
+		time.Sleep(b.messageDelay)
+		return nil
+	})
+}
+
+// Pass feedback on to admins.
+func (b bot) sendFeedback(f Feedback) {
+	b.deliver(func() error {
+		b.feedback <- f
+		return nil
+	})
+}
+
+// Run send right away or, while an event is handled in a transaction, once it is committed.
+func (b bot) deliver(send func() error) {
+	if b.out != nil {
+		*b.out = append(*b.out, send)
+		return
+	}
+	if err := send(); err != nil {
 		b.err.Println("failed to send message:", err)
 	}
 }
@@ -197,6 +300,55 @@ func formatDuration(msg translate.Msg, d time.Duration) string {
 	return s
 }
 
+// Format a session summary using the messages of a user.
+func formatSummary(msg translate.Msg, s brain.Summary) string {
+	text := fmt.Sprintf(msg.SessionSummary, s.Studied, s.Correct*100/s.Studied, s.Learned)
+	if len(s.Hardest) > 0 {
+		text += "\n" + fmt.Sprintf(msg.SessionHardest, strings.Join(s.Hardest, ", "))
+	}
+	if s.Streak > 0 {
+		text += "\n" + formatStreak(msg, s.Streak)
+	}
+	return text
+}
+
+// Format the progress of the daily goals the user has set.
+func formatProgress(msg translate.Msg, p brain.Progress) string {
+	var goals []string
+	if p.StudyGoal > 0 {
+		goals = append(goals, fmt.Sprintf(msg.GoalStudies, p.Studied, p.StudyGoal))
+	}
+	if p.AddGoal > 0 {
+		goals = append(goals, fmt.Sprintf(msg.GoalAdds, p.Added, p.AddGoal))
+	}
+	return fmt.Sprintf(msg.GoalProgress, strings.Join(goals, ", "))
+}
+
+// Format the forecast as one line per day.
+func formatForecast(msg translate.Msg, forecast []brain.ForecastDay) string {
+	lines := make([]string, len(forecast))
+	for i, d := range forecast {
+		day := d.Day.Format(msg.DateFormat)
+		if i == 0 {
+			day = msg.Today
+		}
+		lines[i] = fmt.Sprintf(msg.ForecastDay, day, d.Due)
+		if d.New > 0 {
+			lines[i] += fmt.Sprintf(msg.ForecastNew, d.New)
+		}
+	}
+	return fmt.Sprintf(msg.Forecast, strings.Join(lines, "\n"))
+}
+
+// Format the number of days in a row a user has studied.
+func formatStreak(msg translate.Msg, streak int) string {
+	days := msg.Days
+	if streak == 1 {
+		days = msg.Day
+	}
+	return fmt.Sprintf(msg.Streak, strconv.Itoa(streak)+" "+days)
+}
+
 // Normalize two forms so user can choose to add parts in paranthesis or not.
 // Case, space and punctuation are ignored.
 func normPhrases(s string) (string, string) {
@@ -76,12 +76,12 @@ func (b bot) extractPhrases(u scope.User, links []string) ([]brain.Phrase, strin
 		// Notify admin for unsupported files
 		ext := strings.ToLower(path.Ext(f.Path))
 		if ext != ".csv" && ext != ".txt" && ext != ".tsv" {
-			b.feedback <- Feedback{
+			b.sendFeedback(Feedback{
 				ChatID:   u.ID,
 				Username: u.Name(),
 				Message:  fmt.Sprintf("[unhandled link: %s]", link),
 				Channel:  slackUnhandled,
-			}
+			})
 			continue
 		}
 
@@ -112,7 +112,9 @@ func (b bot) extractPhrases(u scope.User, links []string) ([]brain.Phrase, strin
 		}(file.URL)
 
 		// Separate .tsv and .txt files by tab
+		// Allow rows to have a different number of columns, since the tag columns are optional
 		csvReader := csv.NewReader(res.Body)
+		csvReader.FieldsPerRecord = -1
 		if file.Ext == "tsv" || file.Ext == ".txt" {
 			csvReader.Comma = '\t'
 		}
@@ -127,8 +129,10 @@ func (b bot) extractPhrases(u scope.User, links []string) ([]brain.Phrase, strin
 			continue
 		}
 		// Check formatting
-		if cols := len(records[0]); cols < 2 {
-			return nil, "", fmt.Sprintf(u.Msg.ImportErrCols, file.Name, cols), nil
+		for _, r := range records {
+			if cols := len(r); cols < 2 {
+				return nil, "", fmt.Sprintf(u.Msg.ImportErrCols, file.Name, cols), nil
+			}
 		}
 
 		fileNames = append(fileNames, file.Name)
@@ -141,17 +145,30 @@ func (b bot) extractPhrases(u scope.User, links []string) ([]brain.Phrase, strin
 
 	// Check for duplicates
 	var phrases []brain.Phrase
+Records:
 	for _, r := range allRecords {
 		p := brain.Phrase{
 			Phrase:      strings.TrimSpace(r[0]),
 			Explanation: strings.TrimSpace(r[1]),
 		}
+		// Alternative answers are in the third column, one per line
+		if len(r) > 2 {
+			for _, a := range strings.Split(r[2], "\n") {
+				if a = strings.TrimSpace(a); a != "" {
+					p.Alternatives = append(p.Alternatives, a)
+				}
+			}
+		}
+		// Each further column is a tag
+		if len(r) > 3 {
+			p.Tags = r[3:]
+		}
 
 		// Merge, if duplicate
-		for _, prev := range phrases {
+		for i, prev := range phrases {
 			if p.Explanation == prev.Explanation {
-				prev.Phrase += " (" + p.Phrase + ")"
-				continue
+				phrases[i].Alternatives = append(append(prev.Alternatives, p.Phrase), p.Alternatives...)
+				continue Records
 			}
 		}
 
@@ -29,25 +29,27 @@ func (b bot) handleMessage(u scope.User, msg string) {
 			return
 		}
 		// Score user unput and pick appropriate reply
-		msgNormalizedA, msgNormalizedB := normPhrases(msg)
-		if msgNormalizedA == "" {
+		if msgNormalizedA, _ := normPhrases(msg); msgNormalizedA == "" {
 			study, err := b.store.GetStudy(u.ID)
 			if err != nil {
 				b.send(u.ID, u.Msg.Error, u.Rpl.Show, fmt.Errorf("failed to get study: %v", err))
 				return
 			}
-			b.send(u.ID, study.Phrase, u.Rpl.Score, nil)
+			b.send(u.ID, formatAnswers(study), u.Rpl.Score, nil)
 			return
 		}
-		var score = 1
+		grade := brain.GradeGood
 		reply := u.Msg.StudyCorrect
-		phraseNormalizedA, phraseNormalizedB := normPhrases(study.Phrase)
-		if msgNormalizedA != phraseNormalizedA && msgNormalizedB != phraseNormalizedB {
-			score = -2
-			reply = fmt.Sprintf(u.Msg.StudyWrong, study.Phrase)
+		switch result, closest := checkAnswers(msg, study); result {
+		case answerClose:
+			grade = brain.GradeHard
+			reply = fmt.Sprintf(u.Msg.StudyClose, diffAnswer(msg, closest))
+		case answerWrong:
+			grade = brain.GradeAgain
+			reply = fmt.Sprintf(u.Msg.StudyWrong, formatAnswers(study))
 		}
 		b.send(u.ID, reply, nil, nil)
-		b.send(b.scoreAndStudy(u, score))
+		b.scoreAndStudy(u, grade)
 
 	case brain.ModeAdd:
 		parts := strings.SplitN(strings.TrimSpace(msg), "\n", 2)
@@ -63,15 +65,13 @@ func (b bot) handleMessage(u scope.User, msg string) {
 		explanation := strings.TrimSpace(parts[1])
 
 		// Check for existing explanation
-		p, err := b.store.FindPhrase(u.ID, func(p brain.Phrase) bool {
-			return p.Explanation == explanation
-		})
-		if err != nil {
-			b.send(u.ID, u.Msg.Error, nil, fmt.Errorf("failed to lookup phrase: %v", err))
+		p, err := b.store.FindExplanation(u.ID, explanation)
+		if err == nil {
+			b.send(u.ID, fmt.Sprintf(u.Msg.ExplanationExists, p.Phrase, p.Explanation), u.Rpl.AddMode, nil)
 			return
 		}
-		if p.Phrase != "" {
-			b.send(u.ID, fmt.Sprintf(u.Msg.ExplanationExists, p.Phrase, p.Explanation), u.Rpl.AddMode, nil)
+		if err != brain.ErrNotFound {
+			b.send(u.ID, u.Msg.Error, nil, fmt.Errorf("failed to lookup phrase: %v", err))
 			return
 		}
 
@@ -88,17 +88,17 @@ func (b bot) handleMessage(u scope.User, msg string) {
 		b.messageWelcome(u, "")
 
 	case brain.ModeFeedback:
-		b.feedback <- Feedback{ChatID: u.ID, Username: u.Name(), Message: msg}
+		b.sendFeedback(Feedback{ChatID: u.ID, Username: u.Name(), Message: msg})
 		b.send(u.ID, fmt.Sprintf(u.Msg.FeedbackDone, u.Name()), nil, nil)
 		b.send(b.messageStartMenu(u))
 
 	default:
-		b.feedback <- Feedback{
+		b.sendFeedback(Feedback{
 			ChatID:   u.ID,
 			Username: u.Name(),
 			Message:  msg,
 			Channel:  slackUnhandled,
-		}
+		})
 		b.send(b.messageStartMenu(u))
 	}
 }
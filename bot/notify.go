@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/scope"
 )
 
 // Start a timer to notify the given chat.
@@ -24,11 +25,9 @@ func (b bot) scheduleNotify(id int64) {
 		return
 	}
 
-	if timer := b.notifyTimers[id]; timer != nil {
-		// Don't care if timer is active or not
-		_ = timer.Stop()
-	}
+	b.stopTimer(b.notifyTimers, id)
 	u := b.getUser(id)
+	b.scheduleGoalNudge(u)
 	d, count, err := b.store.GetNotifyTime(id, u.Timezone())
 	if err != nil {
 		b.err.Println(err)
@@ -39,7 +38,7 @@ func (b bot) scheduleNotify(id int64) {
 	}
 
 	b.info.Printf("Notify %d in %s with %d due studies", id, d.String(), count)
-	b.notifyTimers[id] = time.AfterFunc(d, func() {
+	b.startTimer(b.notifyTimers, id, d, func() {
 		b.notify(id, count)
 	})
 }
@@ -61,3 +60,79 @@ func (b bot) notify(id int64, count int) {
 		b.err.Println(err)
 	}
 }
+
+// Start a timer to remind the user of the daily goals in the evening.
+// Only works when the user has set a daily goal.
+func (b bot) scheduleGoalNudge(u scope.User) {
+	b.stopTimer(b.goalTimers, u.ID)
+	d, err := b.store.GetGoalNudgeTime(u.ID, u.Timezone())
+	if err == brain.ErrNotFound {
+		return
+	}
+	if err != nil {
+		b.err.Println(err)
+		return
+	}
+	b.startTimer(b.goalTimers, u.ID, d, func() {
+		b.nudge(u.ID)
+	})
+}
+
+// Remind the user of the daily goals if they haven't been reached yet.
+// The next nudge is scheduled for the following day.
+// Users who haven't read the last notification are not nudged.
+func (b bot) nudge(id int64) {
+	u := b.getUser(id)
+	b.scheduleGoalNudge(u)
+
+	unread, err := b.store.HasUnreadNotify(id)
+	if err != nil {
+		b.err.Println(err)
+		return
+	}
+	if unread {
+		return
+	}
+	p, err := b.store.GetProgress(id, u.Timezone())
+	if err != nil {
+		b.err.Println(err)
+		return
+	}
+	if p.Met() {
+		return
+	}
+	msg := fmt.Sprintf(u.Msg.GoalNudge, u.Name(), formatProgress(u.Msg, p))
+	if err := b.store.SetMode(id, brain.ModeMenu); err != nil {
+		b.err.Printf("failed to activate menu mode while nudging %d: %v", u.ID, err)
+	}
+	if err := b.client.Send(id, msg, u.Rpl.StudiesDue); err != nil {
+		b.err.Printf("failed to nudge user %d: %v", u.ID, err)
+	}
+	b.info.Printf("Nudged %s (%d) with unmet daily goal", u.Name(), u.ID)
+	// Nudges count as notifications, so they stop too when the user isn't reading
+	if err := b.store.TrackNotify(u.ID, time.Now()); err != nil {
+		b.err.Println(err)
+	}
+}
+
+// Stop the timer of a user.
+// Timers are accessed by webhook events and by timers rescheduling themselves.
+func (b bot) stopTimer(timers map[int64]*time.Timer, id int64) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+	if timer := timers[id]; timer != nil {
+		// Don't care if timer is active or not
+		_ = timer.Stop()
+		delete(timers, id)
+	}
+}
+
+// Start a timer for a user, replacing the previous one.
+func (b bot) startTimer(timers map[int64]*time.Timer, id int64, d time.Duration, fn func()) {
+	b.timersMu.Lock()
+	defer b.timersMu.Unlock()
+	if timer := timers[id]; timer != nil {
+		_ = timer.Stop()
+	}
+	timers[id] = time.AfterFunc(d, fn)
+}
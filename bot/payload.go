@@ -2,7 +2,8 @@ package bot
 
 import (
 	"fmt"
-	"time"
+	"strconv"
+	"strings"
 
 	"github.com/jorinvo/slangbrain/brain"
 	"github.com/jorinvo/slangbrain/payload"
@@ -10,13 +11,16 @@ import (
 )
 
 func (b bot) handlePayload(u scope.User, p, referral string) {
-	isDuplicate, err := b.store.IsDuplicate(u.ID, p)
-	if err != nil {
-		b.err.Println(err)
-	}
-	if isDuplicate {
-		b.info.Printf("[id=%d,p=%s] same payload sent twice in a row", u.ID, p)
-		return
+	// Hints are revealed step by step by sending the same payload multiple times
+	if p != payload.Hint {
+		isDuplicate, err := b.store.IsDuplicate(u.ID, p)
+		if err != nil {
+			b.err.Println(err)
+		}
+		if isDuplicate {
+			b.info.Printf("[id=%d,p=%s] same payload sent twice in a row", u.ID, p)
+			return
+		}
 	}
 
 	switch p {
@@ -27,12 +31,27 @@ func (b bot) handlePayload(u scope.User, p, referral string) {
 		b.send(u.ID, u.Msg.Idle, nil, nil)
 
 	case payload.Study:
+		if err := b.store.SetStudyTag(u.ID, ""); err != nil {
+			b.err.Println(err)
+		}
 		if err := b.store.SetMode(u.ID, brain.ModeStudy); err != nil {
 			b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
 			return
 		}
 		b.send(b.startStudy(u))
 
+	case payload.Tags:
+		tags, err := b.store.GetTags(u.ID)
+		if err != nil {
+			b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
+			return
+		}
+		if len(tags) == 0 {
+			b.send(u.ID, u.Msg.TagsEmpty+"\n\n"+u.Msg.Menu, u.Rpl.MenuMode, nil)
+			return
+		}
+		b.send(u.ID, u.Msg.Tags, u.Rpl.StudyTags(tags), nil)
+
 	case payload.Add:
 		if err := b.store.SetMode(u.ID, brain.ModeAdd); err != nil {
 			b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
@@ -55,9 +74,7 @@ func (b bot) handlePayload(u scope.User, p, referral string) {
 			replies = u.Rpl.HelpUnsubscribe
 		}
 		buttons := u.Btn.Help(token)
-		if err = b.client.SendWithButtons(u.ID, u.Msg.Help, replies, buttons); err != nil {
-			b.err.Println("failed to send message:", err)
-		}
+		b.sendWithButtons(u.ID, u.Msg.Help, replies, buttons)
 
 	case payload.ShowPhrase:
 		study, err := b.store.GetStudy(u.ID)
@@ -65,16 +82,49 @@ func (b bot) handlePayload(u scope.User, p, referral string) {
 			b.send(u.ID, u.Msg.Error, u.Rpl.Show, fmt.Errorf("failed to get study: %v", err))
 			return
 		}
-		b.send(u.ID, study.Phrase, u.Rpl.Score, nil)
+		b.send(u.ID, formatAnswers(study), u.Rpl.Score, nil)
+
+	case payload.Hint:
+		study, hints, err := b.store.UseHint(u.ID)
+		if err != nil {
+			b.send(u.ID, u.Msg.Error, u.Rpl.Show, fmt.Errorf("failed to get hint: %v", err))
+			return
+		}
+		if study.Total == 0 {
+			b.send(b.startStudy(u))
+			return
+		}
+		h := hint(study.Phrase, hints)
+		// Nothing left to hint, the phrase is revealed
+		if h == study.Phrase {
+			b.send(u.ID, formatAnswers(study), u.Rpl.Score, nil)
+			return
+		}
+		b.send(u.ID, fmt.Sprintf(u.Msg.Hint, h), u.Rpl.Show, nil)
 
-	case payload.ScoreBad:
-		b.send(b.scoreAndStudy(u, -2))
+	case payload.ScoreAgain, payload.ScoreBad:
+		b.scoreAndStudy(u, brain.GradeAgain)
 
-	case payload.ScoreOk:
-		b.send(b.scoreAndStudy(u, 0))
+	case payload.ScoreHard, payload.ScoreOk:
+		b.scoreAndStudy(u, brain.GradeHard)
 
 	case payload.ScoreGood:
-		b.send(b.scoreAndStudy(u, 1))
+		b.scoreAndStudy(u, brain.GradeGood)
+
+	case payload.ScoreEasy:
+		b.scoreAndStudy(u, brain.GradeEasy)
+
+	case payload.Undo:
+		if err := b.store.UndoLastStudy(u.ID); err == brain.ErrNotFound {
+			b.send(u.ID, u.Msg.UndoEmpty, nil, nil)
+		} else if err != nil {
+			b.send(u.ID, u.Msg.Error, u.Rpl.StudyMode, err)
+			return
+		}
+		b.send(b.startStudy(u))
+
+	case payload.Continue:
+		b.send(b.startStudy(u))
 
 	case payload.Subscribe:
 		if err := b.store.Subscribe(u.ID); err != nil {
@@ -102,7 +152,7 @@ func (b bot) handlePayload(u scope.User, p, referral string) {
 
 	case payload.ImportHelp:
 		b.send(u.ID, u.Msg.ImportHelp1, nil, nil)
-		time.Sleep(b.messageDelay)
+		b.wait()
 		b.send(u.ID, u.Msg.ImportHelp2, u.Rpl.ImportHelp, nil)
 
 	case payload.ConfirmImport:
@@ -121,9 +171,79 @@ func (b bot) handlePayload(u scope.User, p, referral string) {
 		b.send(u.ID, fmt.Sprintf(u.Msg.APIToken, t), nil, err)
 		b.send(b.messageStartMenu(u))
 
+	case payload.Forecast:
+		forecast, err := b.store.Forecast(u.ID, brain.ForecastDays)
+		if err != nil {
+			b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
+			return
+		}
+		b.send(u.ID, formatForecast(u.Msg, forecast), nil, nil)
+		b.send(b.messageStartMenu(u))
+
 	case payload.Menu:
-		fallthrough
+		b.send(b.messageStartMenu(u))
+
 	default:
+		if strings.HasPrefix(p, payload.StudyTag) {
+			if err := b.store.SetStudyTag(u.ID, strings.TrimPrefix(p, payload.StudyTag)); err != nil {
+				b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
+				return
+			}
+			if err := b.store.SetMode(u.ID, brain.ModeStudy); err != nil {
+				b.send(u.ID, u.Msg.Error, u.Rpl.MenuMode, err)
+				return
+			}
+			b.send(b.startStudy(u))
+			return
+		}
+		if strings.HasPrefix(p, payload.Choice) {
+			study, options, err := b.store.GetChoices(u.ID)
+			if err != nil {
+				b.send(u.ID, u.Msg.Error, u.Rpl.StudyMode, fmt.Errorf("failed to get study: %v", err))
+				return
+			}
+			// Option of a study that is already done
+			// or of a payload from before options were identified by index
+			card, option, err := payload.DecodeChoice(p)
+			if err != nil || study.Total == 0 || study.Card != card {
+				b.send(b.startStudy(u))
+				return
+			}
+			if option >= 0 && option < len(options) && options[option] == study.Phrase {
+				b.send(u.ID, u.Msg.StudyCorrect, nil, nil)
+				b.scoreAndStudy(u, brain.GradeGood)
+				return
+			}
+			b.send(u.ID, fmt.Sprintf(u.Msg.StudyWrong, formatAnswers(study)), nil, nil)
+			b.scoreAndStudy(u, brain.GradeAgain)
+			return
+		}
+		if strings.HasPrefix(p, payload.Suspend) {
+			seq, err := strconv.Atoi(strings.TrimPrefix(p, payload.Suspend))
+			if err == nil {
+				err = b.store.SuspendPhrase(u.ID, seq, true)
+			}
+			if err != nil {
+				b.send(u.ID, u.Msg.Error, u.Rpl.StudyMode, err)
+				return
+			}
+			b.send(u.ID, u.Msg.Suspended, nil, nil)
+			b.send(b.startStudy(u))
+			return
+		}
+		if strings.HasPrefix(p, payload.Bury) {
+			seq, err := strconv.Atoi(strings.TrimPrefix(p, payload.Bury))
+			if err == nil {
+				err = b.store.BuryPhrase(u.ID, seq, u.Timezone())
+			}
+			if err != nil {
+				b.send(u.ID, u.Msg.Error, u.Rpl.StudyMode, err)
+				return
+			}
+			b.send(u.ID, u.Msg.Buried, nil, nil)
+			b.send(b.startStudy(u))
+			return
+		}
 		b.send(b.messageStartMenu(u))
 	}
 }
@@ -15,6 +15,15 @@ var (
 	ErrNotFound = errors.New("not found")
 	// ErrNotReady signals that the requested data is not ready.
 	ErrNotReady = errors.New("not ready")
+	// ErrInvalidCursor signals that a cursor to continue a query is malformed.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+const (
+	// ForecastDays is the default number of days a forecast is made for.
+	ForecastDays = 7
+	// MaxForecastDays is the maximum number of days a forecast can be made for.
+	MaxForecastDays = 90
 )
 
 // Mode is the state of a chat.
@@ -34,12 +43,39 @@ const (
 	ModeFeedback
 )
 
+// Grade is the answer a user gives for a study.
+type Grade int
+
+const (
+	// GradeAgain means the user didn't know the phrase.
+	GradeAgain Grade = iota + 1
+	// GradeHard means the user remembered the phrase only with difficulties.
+	GradeHard
+	// GradeGood means the user knew the phrase.
+	GradeGood
+	// GradeEasy means the user knew the phrase without thinking about it.
+	GradeEasy
+)
+
 // Study is a study the current study the user needs to answer.
 type Study struct {
+	// Card identifies the card that is studied.
+	// It is the ID of the phrase with the flags for reverse and cloze cards.
+	Card int64
 	// Phrase is the phrase the user needs to guess.
+	// For reverse studies it is the explanation of the phrase.
 	Phrase string
+	// Alternatives are other accepted answers of the phrase.
+	// Reverse studies have no alternatives.
+	Alternatives []string
 	// Explanation is the explanation displayed to the user.
+	// For reverse studies it is the phrase.
 	Explanation string
+	// Reverse is set if the user is asked for the explanation of a phrase.
+	Reverse bool
+	// Cloze is set if the user is asked for the hidden words of a cloze.
+	// Then Phrase contains the hidden words and Explanation the phrase with a blank.
+	Cloze bool
 	// Total is the total number of studies ready, including the current one.
 	Total int
 	// Next contains the time until the next study is available;
@@ -52,6 +88,24 @@ type Phrase struct {
 	Phrase      string `json:"phrase,omitempty"`
 	Explanation string `json:"explanation,omitempty"`
 	Score       int    `json:"score,omitempty"`
+	// Memory is managed by the Scheduler of the user.
+	Memory Memory `json:"-"`
+	// Direction decides if the phrase is also studied in reverse.
+	Direction Direction `json:"direction,omitempty"`
+	// ReverseScore and ReverseMemory belong to the reverse card of the phrase.
+	ReverseScore  int    `json:"reverseScore,omitempty"`
+	ReverseMemory Memory `json:"-"`
+	// ClozeScores and ClozeMemories belong to the cloze cards of the phrase.
+	// They are indexed by cloze number - 1.
+	ClozeScores   []int    `json:"clozeScores,omitempty"`
+	ClozeMemories []Memory `json:"-"`
+	// Alternatives are other answers accepted for the phrase.
+	Alternatives []string `json:"alternatives,omitempty"`
+	// Tags can be used to organize phrases and to study only some of them.
+	Tags []string `json:"tags,omitempty"`
+	// Leech is set if the phrase has been failed too often in a row.
+	// It is reset when the phrase is updated.
+	Leech bool `json:"leech,omitempty"`
 }
 
 // Stats describes statistics for a single user.
@@ -64,6 +118,81 @@ type Stats struct {
 	Score int
 	// Rank is the rank by score of a user compared to all other users.
 	Rank int
+	// Percentile is the percentage of users with a lower or the same score.
+	Percentile int
+	// MonthRank is the rank by score compared to the users who registered in the same month.
+	// It is 0 for users without register date.
+	MonthRank int
+	// Streak is the number of days in a row the user has studied.
+	Streak int
+}
+
+// Summary describes the most recent study session of a user.
+type Summary struct {
+	// Studied is the number of studies in the session.
+	Studied int
+	// Correct is the number of studies that haven't been failed.
+	Correct int
+	// Learned is the number of cards that got their first point in the session.
+	Learned int
+	// Hardest contains the phrases that have been failed most often in the session.
+	Hardest []string
+	// Streak is the number of days in a row the user has studied.
+	Streak int
+}
+
+// Progress describes how far a user got with the daily goals today.
+// Goals are 0 if the user hasn't set them.
+type Progress struct {
+	Studied   int
+	StudyGoal int
+	Added     int
+	AddGoal   int
+}
+
+// HasGoal is true if the user has set any daily goal.
+func (p Progress) HasGoal() bool {
+	return p.StudyGoal > 0 || p.AddGoal > 0
+}
+
+// Met is true if all daily goals have been reached.
+func (p Progress) Met() bool {
+	return p.Studied >= p.StudyGoal && p.Added >= p.AddGoal
+}
+
+// ForecastDay is the expected study load of a user on a single day.
+type ForecastDay struct {
+	// Day is the start of the day in the user's timezone.
+	Day time.Time `json:"day"`
+	// Due is the number of studies scheduled for the day.
+	Due int `json:"due"`
+	// New is the number of new phrases expected to be introduced on the day.
+	New int `json:"new"`
+}
+
+// Review is a single study of a phrase.
+type Review struct {
+	Time time.Time `json:"time"`
+	// Grade is 0 for studies before grades have been introduced.
+	Grade Grade `json:"grade"`
+	// Score is the score of the studied card after the study.
+	Score int `json:"score"`
+	// Failed is set if the card wasn't known.
+	Failed bool `json:"failed"`
+	// Reverse and Cloze describe which card of the phrase has been studied.
+	Reverse bool `json:"reverse,omitempty"`
+	Cloze   int  `json:"cloze,omitempty"`
+}
+
+// PhraseStats are derived from the reviews of a phrase.
+type PhraseStats struct {
+	Reviews int `json:"reviews"`
+	// Lapses is the number of failed reviews of cards that had a score.
+	Lapses int `json:"lapses"`
+	// SuccessRate is the share of reviews that didn't fail, between 0 and 1.
+	SuccessRate float64 `json:"successRate"`
+	// AverageInterval is the average number of days between two reviews of the same card.
+	AverageInterval float64 `json:"averageInterval"`
 }
 
 // Profile abstracts a user profile.
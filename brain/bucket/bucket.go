@@ -5,6 +5,7 @@ package bucket
 // phrase is a bucket sequence as uint64
 // scoreupdate is an int64
 // newscore is an int64
+// grade is an int64
 var (
 	// Modes maps id -> Mode.
 	Modes = []byte("modes")
@@ -12,8 +13,17 @@ var (
 	Phrases = []byte("phrases")
 	// id+phrase -> time
 	Studytimes = []byte("studytimes")
+	// Dues maps id+time+phrase -> ''.
+	// It indexes Studytimes by time to find due studies without scanning all phrases of a user.
+	Dues = []byte("dues")
 	// id+phrase -> time
 	PhraseAddTimes = []byte("phraseaddtimes")
+	// AddedPhrases maps id+time+phrase -> ''.
+	// It indexes PhraseAddTimes by time to page through phrases without sorting all phrases of a user.
+	AddedPhrases = []byte("addedphrases")
+	// ScorePhrases maps id+score+phrase -> ''.
+	// It indexes the score of phrases to page through them without decoding all phrases of a user.
+	ScorePhrases = []byte("scorephrases")
 	// id -> phrase+phrase+phrase+...
 	NewPhrases = []byte("newphrases")
 	// id -> time
@@ -30,10 +40,20 @@ var (
 	Stattimes = []byte("stattimes")
 	// Scoretotals maps id -> int64.
 	Scoretotals = []byte("scoretotals")
+	// Ranks maps cohort+node -> int64.
+	// Each cohort is a Fenwick tree counting users by scoretotal.
+	// cohort is 0 for all users or the month users registered in as year*12+month.
+	Ranks = []byte("ranks")
 	// Zeroscores maps id -> int64.
 	Zeroscores = []byte("zeroscores")
-	// Studies maps id+time -> phrase+scoreupdate+newscore.
+	// Studies maps id+time+seq -> phrase+scoreupdate+newscore+grade+hints.
+	// seq is a bucket sequence, so studies in the same second don't overwrite each other.
+	// Studies before grades have been introduced have no grade.
+	// Studies before hints have been introduced have no hints.
 	Studies = []byte("studies")
+	// PhraseStudies maps id+phrase+time+seq -> phrase+scoreupdate+newscore+grade+hints.
+	// It holds a copy of Studies by phrase to get the history of a phrase without scanning all studies of a user.
+	PhraseStudies = []byte("phrasestudies")
 	// MessageIDs maps string -> time.
 	MessageIDs = []byte("messageids")
 	// AuthTokens maps token -> id.
@@ -48,6 +68,36 @@ var (
 	Imports = []byte("imports")
 	// Notifies maps id -> int64.
 	Notifies = []byte("notifies")
+	// Schedulers maps id -> string(scheduler).
+	Schedulers = []byte("schedulers")
+	// Tags maps id+string(tag)+0+phrase -> ''.
+	Tags = []byte("tags")
+	// Explanations maps id+string(explanation)+0+phrase -> ''.
+	// The explanation is normalized to find duplicates.
+	Explanations = []byte("explanations")
+	// StudyTags maps id -> string(tag).
+	StudyTags = []byte("studytags")
+	// Undos maps id -> time+seq+phrase+score+time+leech+gob(Memory).
+	// It stores the time and sequence of the last study and the state of the card before that study.
+	// leech is 1 if the phrase has been a leech before the study and 0 otherwise.
+	Undos = []byte("undos")
+	// Suspended maps id+phrase -> ''.
+	Suspended = []byte("suspended")
+	// Trash maps id+phrase -> time+gob(trashed).
+	// It keeps deleted phrases with the state needed to restore them until they are purged.
+	// time is when the phrase has been deleted.
+	Trash = []byte("trash")
+	// Settings maps id -> gob(Settings).
+	Settings = []byte("settings")
+	// Hints maps id -> phrase+int64.
+	// It counts the hints used for the current study.
+	Hints = []byte("hints")
+	// Streaks maps id -> day+int64.
+	// day is the number of days since the unix epoch in the user's timezone.
+	Streaks = []byte("streaks")
+	// Meta maps name -> value for data about the database itself.
+	// "version" -> int64 is the schema version, the number of applied migrations.
+	Meta = []byte("meta")
 )
 
 // All is a list of all bucket names.
@@ -55,7 +105,10 @@ var All = [][]byte{
 	Modes,
 	Phrases,
 	Studytimes,
+	Dues,
 	PhraseAddTimes,
+	AddedPhrases,
+	ScorePhrases,
 	NewPhrases,
 	Reads,
 	Activities,
@@ -64,8 +117,10 @@ var All = [][]byte{
 	RegisterDates,
 	Stattimes,
 	Scoretotals,
+	Ranks,
 	Zeroscores,
 	Studies,
+	PhraseStudies,
 	MessageIDs,
 	AuthTokens,
 	AuthUsers,
@@ -73,4 +128,15 @@ var All = [][]byte{
 	PrevPayloads,
 	Imports,
 	Notifies,
+	Schedulers,
+	Tags,
+	Explanations,
+	StudyTags,
+	Undos,
+	Suspended,
+	Trash,
+	Settings,
+	Hints,
+	Streaks,
+	Meta,
 }
@@ -0,0 +1,255 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Direction describes which way a phrase is studied.
+// Each direction of a phrase is a separate card with its own score and study time.
+type Direction int
+
+const (
+	// DirectionForward shows the explanation and asks for the phrase.
+	DirectionForward Direction = iota
+	// DirectionReverse shows the phrase and asks for the explanation.
+	DirectionReverse
+	// DirectionBoth studies the phrase in both directions.
+	DirectionBoth
+)
+
+var directionNames = []string{"forward", "reverse", "both"}
+
+// MarshalText implements encoding.TextMarshaler to use direction names in JSON.
+func (d Direction) MarshalText() ([]byte, error) {
+	if d < 0 || int(d) >= len(directionNames) {
+		return nil, fmt.Errorf("invalid direction %d", d)
+	}
+	return []byte(directionNames[d]), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler to use direction names in JSON.
+func (d *Direction) UnmarshalText(b []byte) error {
+	for i, name := range directionNames {
+		if string(b) == name {
+			*d = Direction(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid direction '%s'", b)
+}
+
+// Cards use the ID of their phrase.
+// Reverse cards have the highest bit of the ID set.
+// Cloze cards store the number of their cloze in the other bits of the highest byte.
+// Phrase IDs are bucket sequences that never get that big.
+// This way card keys have the same length as phrase keys
+// and forward cards of existing phrases don't need to change.
+const (
+	reverseFlag = 0x80
+	clozeMask   = 0x7f
+)
+
+// Get the key for the card of a phrase.
+func cardKey(phraseKey []byte, reverse bool) []byte {
+	k := append([]byte{}, phraseKey...)
+	if reverse {
+		k[8] |= reverseFlag
+	}
+	return k
+}
+
+// Get the key for the card of cloze n of a phrase.
+func clozeKey(phraseKey []byte, n int) []byte {
+	k := append([]byte{}, phraseKey...)
+	k[8] |= byte(n) & clozeMask
+	return k
+}
+
+// Get the key of the phrase a card belongs to.
+// Also returns whether the card is a reverse card.
+func phraseKey(cardKey []byte) ([]byte, bool) {
+	k := append([]byte{}, cardKey...)
+	reverse := k[8]&reverseFlag != 0
+	k[8] &^= reverseFlag | clozeMask
+	return k, reverse
+}
+
+// Get the cloze number of a card.
+// Returns 0 if the card is no cloze card.
+func cardCloze(cardKey []byte) int {
+	return int(cardKey[8] & clozeMask)
+}
+
+// Get the keys of all cards of a phrase.
+// Phrases with clozes have a card for each cloze number and ignore the direction.
+func (p Phrase) cards(key []byte) [][]byte {
+	var cards [][]byte
+	if numbers := clozeNumbers(p.Phrase); len(numbers) > 0 {
+		for _, n := range numbers {
+			cards = append(cards, clozeKey(key, n))
+		}
+		return cards
+	}
+	for _, reverse := range []bool{false, true} {
+		if p.Direction.has(reverse) {
+			cards = append(cards, cardKey(key, reverse))
+		}
+	}
+	return cards
+}
+
+// Returns whether a direction includes the reverse or the forward card.
+func (d Direction) has(reverse bool) bool {
+	if reverse {
+		return d != DirectionForward
+	}
+	return d != DirectionReverse
+}
+
+// Get pointers to score and memory of a card of the phrase.
+func (p *Phrase) card(cardKey []byte) (*int, *Memory) {
+	if n := cardCloze(cardKey); n > 0 {
+		for len(p.ClozeScores) < n {
+			p.ClozeScores = append(p.ClozeScores, 0)
+		}
+		for len(p.ClozeMemories) < n {
+			p.ClozeMemories = append(p.ClozeMemories, Memory{})
+		}
+		return &p.ClozeScores[n-1], &p.ClozeMemories[n-1]
+	}
+	if cardKey[8]&reverseFlag != 0 {
+		return &p.ReverseScore, &p.ReverseMemory
+	}
+	return &p.Score, &p.Memory
+}
+
+// SetDirection changes the direction a phrase is studied in.
+// Added cards are queued as new phrases, removed cards lose their score.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) SetDirection(id int64, seq int, d Direction) error {
+	if d < DirectionForward || d > DirectionBoth {
+		return fmt.Errorf("failed to set direction for phrase %d of %d: invalid direction %d", seq, id, d)
+	}
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		p, err := getPhrase(tx, key)
+		if err != nil {
+			return err
+		}
+		if p.Direction == d {
+			return nil
+		}
+
+		prev := p
+		p.Direction = d
+		if err := updateCards(tx, key, prev, &p); err != nil {
+			return err
+		}
+		return putPhrase(tx, key, p)
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to set direction for key %x to %d: %v", key, d, err)
+	}
+	return err
+}
+
+// Update the cards of a phrase that changed from prev to p.
+// Removed cards lose their score, added cards are queued as new phrases.
+// The phrase itself is not saved.
+func updateCards(tx kv.Tx, key []byte, prev Phrase, p *Phrase) error {
+	had, has := map[string]bool{}, map[string]bool{}
+	for _, card := range prev.cards(key) {
+		had[string(card)] = true
+	}
+	for _, card := range p.cards(key) {
+		has[string(card)] = true
+	}
+	for _, card := range prev.cards(key) {
+		if has[string(card)] {
+			continue
+		}
+		if err := removeCard(tx, card, p); err != nil {
+			return err
+		}
+	}
+	for _, card := range p.cards(key) {
+		if had[string(card)] {
+			continue
+		}
+		if err := queueCard(tx, card, time.Now().Add(studyIntervals[0])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Queue a card as new phrase and try to schedule new phrases.
+func queueCard(tx kv.Tx, card []byte, studyTime time.Time) error {
+	prefix := card[:8]
+	bn := tx.Bucket(bucket.NewPhrases)
+	bz := tx.Bucket(bucket.Zeroscores)
+
+	if err := bn.Put(prefix, append(append([]byte{}, bn.Get(prefix)...), card[8:]...)); err != nil {
+		return err
+	}
+
+	var zeroscore int64
+	if v := bz.Get(prefix); v != nil {
+		zeroscore = btoi(v)
+	}
+	scheduled, err := scheduleNewPhrases(tx, prefix, studyTime, int(zeroscore))
+	if err != nil {
+		return err
+	}
+
+	return bz.Put(prefix, itob(zeroscore+int64(scheduled)))
+}
+
+// Remove a card from study times or new phrases.
+// Resets score and memory of the card and updates scoretotal and zeroscore.
+// The phrase itself is not saved.
+func removeCard(tx kv.Tx, card []byte, p *Phrase) error {
+	prefix := card[:8]
+	score, memory := p.card(card)
+	scheduled := tx.Bucket(bucket.Studytimes).Get(card) != nil
+
+	// Remove from schedule before updating zeroscore,
+	// which might schedule new phrases.
+	if err := deleteStudytime(tx, card); err != nil {
+		return err
+	}
+	if err := removeNewPhrase(tx, prefix, card[8:]); err != nil {
+		return err
+	}
+
+	// Suspended cards don't count as zeroscore
+	if scheduled && *score == 0 && !isSuspended(tx, card) {
+		if err := updateZeroscore(tx, prefix, -1); err != nil {
+			return err
+		}
+	}
+	if err := updateScoretotal(tx, prefix, -*score); err != nil {
+		return err
+	}
+
+	*score = 0
+	*memory = Memory{}
+	return nil
+}
+
+// Remove a card ID from the new phrases of a user.
+func removeNewPhrase(tx kv.Tx, prefix, cardID []byte) error {
+	bn := tx.Bucket(bucket.NewPhrases)
+	v := bn.Get(prefix)
+	for o := 0; o+8 <= len(v); o += 8 {
+		if bytes.Equal(v[o:o+8], cardID) {
+			return bn.Put(prefix, append(append([]byte{}, v[:o]...), v[o+8:]...))
+		}
+	}
+	return nil
+}
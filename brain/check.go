@@ -0,0 +1,549 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Problem is an inconsistency between buckets found by Check.
+type Problem struct {
+	// ID is the chat ID of the user the problem belongs to.
+	// It is 0 for problems affecting all users.
+	ID int64
+	// Bucket is the name of the bucket that is changed by the repair.
+	Bucket string
+	// Message describes the problem.
+	Message string
+	fix     func(tx kv.Tx) error
+}
+
+func (p Problem) String() string {
+	return p.Bucket + ": " + p.Message
+}
+
+type problemsByID []Problem
+
+func (p problemsByID) Len() int {
+	return len(p)
+}
+
+func (p problemsByID) Less(i, j int) bool {
+	return p[i].ID < p[j].ID
+}
+
+func (p problemsByID) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+// Check cross-checks all buckets and returns the problems found ordered by user.
+// With repair set, all problems are fixed in a single transaction.
+func (store Store) Check(repair bool) ([]Problem, error) {
+	var problems []Problem
+	run := store.db.View
+	if repair {
+		run = store.db.Update
+	}
+	err := run(func(tx kv.Tx) error {
+		var err error
+		if problems, err = check(tx); err != nil || !repair {
+			return err
+		}
+		// Fixes depend on each other, the order they were found in matters
+		for _, p := range problems {
+			if err := p.fix(tx); err != nil {
+				return fmt.Errorf("failed to repair %d: %s: %v", p.ID, p, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check database: %v", err)
+	}
+	sort.Stable(problemsByID(problems))
+	return problems, nil
+}
+
+type checker struct {
+	problems []Problem
+}
+
+// Record a problem.
+// Keys used by fix must be copied, since they are only valid while not modifying the DB.
+func (c *checker) add(prefix []byte, b []byte, fix func(kv.Tx) error, format string, args ...interface{}) {
+	var id int64
+	if prefix != nil {
+		id = btoi(prefix)
+	}
+	c.problems = append(c.problems, Problem{id, string(b), fmt.Sprintf(format, args...), fix})
+}
+
+// Find all problems and how to fix them without changing anything.
+func check(tx kv.Tx) ([]Problem, error) {
+	var c checker
+
+	// Collect the expected state from all phrases.
+	// Keys are kept in order to find problems in a stable order.
+	phrases := map[string]Phrase{}
+	var phraseKeys, cards []string
+	scores := map[string]int{}
+	totals := map[string]int{}
+	err := tx.Bucket(bucket.Phrases).ForEach(func(k, v []byte) error {
+		var p Phrase
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+			return fmt.Errorf("gob decode phrase at %x: %v", k, err)
+		}
+		phrases[string(k)] = p
+		phraseKeys = append(phraseKeys, string(k))
+		for _, card := range p.cards(k) {
+			score, _ := p.card(card)
+			scores[string(card)] = *score
+			cards = append(cards, string(card))
+			totals[string(k[:8])] += *score
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := c.checkStudytimes(tx, scores)
+	c.checkDues(tx)
+	queued := c.checkNewPhrases(tx, scores, scheduled)
+	c.checkCards(cards, scheduled, queued)
+	c.checkPhraseIndexes(tx, phrases, phraseKeys)
+	c.checkScoretotals(tx, totals)
+	c.checkZeroscores(tx, scores, scheduled)
+	c.checkAuth(tx)
+	c.checkOrphans(tx, phrases)
+	// Must be last, since repairing scoretotals changes ranks
+	c.checkRanks(tx)
+
+	return c.problems, nil
+}
+
+// Study times must belong to existing cards and be in the due index.
+// Returns the scheduled cards.
+func (c *checker) checkStudytimes(tx kv.Tx, scores map[string]int) map[string]bool {
+	scheduled := map[string]bool{}
+	bd := tx.Bucket(bucket.Dues)
+	tx.Bucket(bucket.Studytimes).ForEach(func(k, v []byte) error {
+		card := append([]byte{}, k...)
+		if _, ok := scores[string(k)]; !ok {
+			c.add(card[:8], bucket.Studytimes, func(tx kv.Tx) error {
+				return deleteStudytime(tx, card)
+			}, "orphaned study time of %s", cardName(card))
+			return nil
+		}
+		scheduled[string(k)] = true
+		if due := dueKey(card, btoi(v)); bd.Get(due) == nil {
+			c.add(card[:8], bucket.Dues, func(tx kv.Tx) error {
+				return tx.Bucket(bucket.Dues).Put(due, []byte{})
+			}, "%s is missing in due index", cardName(card))
+		}
+		return nil
+	})
+	return scheduled
+}
+
+// The due index must not contain entries without matching study time.
+func (c *checker) checkDues(tx kv.Tx) {
+	bs := tx.Bucket(bucket.Studytimes)
+	tx.Bucket(bucket.Dues).ForEach(func(k, _ []byte) error {
+		card := dueCard(k)
+		if v := bs.Get(card); v == nil || !bytes.Equal(v, k[8:16]) {
+			due := append([]byte{}, k...)
+			c.add(card[:8], bucket.Dues, func(tx kv.Tx) error {
+				return tx.Bucket(bucket.Dues).Delete(due)
+			}, "stale due index entry for %s", cardName(card))
+		}
+		return nil
+	})
+}
+
+// New phrases must be existing cards that are not scheduled yet.
+// Returns the queued cards.
+func (c *checker) checkNewPhrases(tx kv.Tx, scores map[string]int, scheduled map[string]bool) map[string]bool {
+	queued := map[string]bool{}
+	tx.Bucket(bucket.NewPhrases).ForEach(func(k, v []byte) error {
+		prefix := append([]byte{}, k...)
+		for o := 0; o+8 <= len(v); o += 8 {
+			card := append(append([]byte{}, prefix...), v[o:o+8]...)
+			problem := ""
+			if _, ok := scores[string(card)]; !ok {
+				problem = "orphaned new phrase %s"
+			} else if scheduled[string(card)] {
+				problem = "new phrase %s is already scheduled"
+			} else if queued[string(card)] {
+				problem = "duplicate new phrase %s"
+			}
+			if problem == "" {
+				queued[string(card)] = true
+				continue
+			}
+			c.add(prefix, bucket.NewPhrases, func(tx kv.Tx) error {
+				return removeNewPhrase(tx, prefix, card[8:])
+			}, problem, cardName(card))
+		}
+		return nil
+	})
+	return queued
+}
+
+// Each card must either be scheduled or queued as new phrase.
+func (c *checker) checkCards(cards []string, scheduled, queued map[string]bool) {
+	for _, k := range cards {
+		if scheduled[k] || queued[k] {
+			continue
+		}
+		card := []byte(k)
+		c.add(card[:8], bucket.NewPhrases, func(tx kv.Tx) error {
+			bn := tx.Bucket(bucket.NewPhrases)
+			return bn.Put(card[:8], append(append([]byte{}, bn.Get(card[:8])...), card[8:]...))
+		}, "%s is neither scheduled nor new", cardName(card))
+	}
+}
+
+// Add times, scores, suspensions, explanations, tags and studies must match the phrases.
+func (c *checker) checkPhraseIndexes(tx kv.Tx, phrases map[string]Phrase, phraseKeys []string) {
+	explanations := map[string][]byte{}
+	tags := map[string][]byte{}
+	added := map[string][]byte{}
+	scores := map[string][]byte{}
+	ba := tx.Bucket(bucket.PhraseAddTimes)
+	for k, p := range phrases {
+		key := []byte(k)
+		scores[string(sortKey(key, int64(p.Score)))] = []byte{}
+		// Missing add times are indexed when they are repaired
+		if v := ba.Get(key); v != nil {
+			added[string(sortKey(key, btoi(v)))] = []byte{}
+		}
+		if p.Explanation != "" {
+			explanations[string(explanationKey(key[:8], p.Explanation, key[8:]))] = []byte{}
+		}
+		for _, tag := range p.Tags {
+			tags[string(tagKey(key[:8], tag, key[8:]))] = []byte{}
+		}
+	}
+
+	// Missing add times are set to now
+	now := time.Now().Unix()
+	for _, k := range phraseKeys {
+		if ba.Get([]byte(k)) != nil {
+			continue
+		}
+		key := []byte(k)
+		c.add(key[:8], bucket.PhraseAddTimes, func(tx kv.Tx) error {
+			return setAddTime(tx, key, now)
+		}, "phrase %d is missing add time", btoi(key[8:]))
+	}
+
+	for _, b := range [][]byte{bucket.PhraseAddTimes, bucket.Suspended} {
+		b := b
+		tx.Bucket(b).ForEach(func(k, _ []byte) error {
+			if _, ok := phrases[string(k)]; ok {
+				return nil
+			}
+			key := append([]byte{}, k...)
+			c.add(key[:8], b, func(tx kv.Tx) error {
+				return tx.Bucket(b).Delete(key)
+			}, "orphaned phrase %d", btoi(key[8:]))
+			return nil
+		})
+	}
+
+	// Phrases in the trash keep their studies until they are purged
+	trashed := map[string]bool{}
+	tx.Bucket(bucket.Trash).ForEach(func(k, _ []byte) error {
+		if _, ok := phrases[string(k)]; !ok {
+			trashed[string(k)] = true
+			return nil
+		}
+		key := append([]byte{}, k...)
+		c.add(key[:8], bucket.Trash, func(tx kv.Tx) error {
+			return tx.Bucket(bucket.Trash).Delete(key)
+		}, "phrase %d is in trash but not deleted", btoi(key[8:]))
+		return nil
+	})
+
+	studies := map[string][]byte{}
+	tx.Bucket(bucket.Studies).ForEach(func(k, v []byte) error {
+		card := append(append([]byte{}, k[:8]...), v[:8]...)
+		pk, _ := phraseKey(card)
+		if _, ok := phrases[string(pk)]; ok || trashed[string(pk)] {
+			studies[string(phraseStudyKey(card, k[8:]))] = append([]byte{}, v...)
+		}
+		return nil
+	})
+
+	c.checkIndex(tx, bucket.AddedPhrases, added)
+	c.checkIndex(tx, bucket.ScorePhrases, scores)
+	c.checkIndex(tx, bucket.Explanations, explanations)
+	c.checkIndex(tx, bucket.Tags, tags)
+	c.checkIndex(tx, bucket.PhraseStudies, studies)
+}
+
+// Make sure an index bucket contains exactly the expected keys and values.
+// Keys start with a user ID.
+func (c *checker) checkIndex(tx kv.Tx, b []byte, expected map[string][]byte) {
+	found := map[string]bool{}
+	tx.Bucket(b).ForEach(func(k, v []byte) error {
+		key := append([]byte{}, k...)
+		if value, ok := expected[string(k)]; ok {
+			found[string(k)] = true
+			if !bytes.Equal(v, value) {
+				c.add(key[:8], b, func(tx kv.Tx) error {
+					return tx.Bucket(b).Put(key, value)
+				}, "outdated index entry %q", key[8:])
+			}
+			return nil
+		}
+		c.add(key[:8], b, func(tx kv.Tx) error {
+			return tx.Bucket(b).Delete(key)
+		}, "stale index entry %q", key[8:])
+		return nil
+	})
+	var missing []string
+	for k := range expected {
+		if !found[k] {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+	for _, k := range missing {
+		key := []byte(k)
+		value := expected[k]
+		c.add(key[:8], b, func(tx kv.Tx) error {
+			return tx.Bucket(b).Put(key, value)
+		}, "missing index entry %q", key[8:])
+	}
+}
+
+// Score totals must be the sum of the scores of all cards of a user.
+func (c *checker) checkScoretotals(tx kv.Tx, totals map[string]int) {
+	bt := tx.Bucket(bucket.Scoretotals)
+	bt.ForEach(func(k, _ []byte) error {
+		if _, ok := totals[string(k)]; !ok {
+			totals[string(k)] = 0
+		}
+		return nil
+	})
+	for _, k := range sortedKeys(totals) {
+		prefix := []byte(k)
+		actual := 0
+		v := bt.Get(prefix)
+		if v != nil {
+			actual = int(btoi(v))
+		}
+		if expected := totals[k]; expected != actual {
+			c.add(prefix, bucket.Scoretotals, func(tx kv.Tx) error {
+				return updateScoretotal(tx, prefix, expected-actual)
+			}, "scoretotal is %d instead of %d", actual, expected)
+		}
+	}
+}
+
+// Zeroscores must count the scheduled cards with a score of 0 that are not suspended.
+func (c *checker) checkZeroscores(tx kv.Tx, scores map[string]int, scheduled map[string]bool) {
+	zeroscores := map[string]int{}
+	bz := tx.Bucket(bucket.Zeroscores)
+	bz.ForEach(func(k, _ []byte) error {
+		zeroscores[string(k)] = 0
+		return nil
+	})
+	for card := range scheduled {
+		if scores[card] == 0 && !isSuspended(tx, []byte(card)) {
+			zeroscores[card[:8]]++
+		}
+	}
+	for _, k := range sortedKeys(zeroscores) {
+		prefix := []byte(k)
+		actual := 0
+		if v := bz.Get(prefix); v != nil {
+			actual = int(btoi(v))
+		}
+		if expected := zeroscores[k]; expected != actual {
+			c.add(prefix, bucket.Zeroscores, func(tx kv.Tx) error {
+				return tx.Bucket(bucket.Zeroscores).Put(prefix, itob(int64(expected)))
+			}, "zeroscore is %d instead of %d", actual, expected)
+		}
+	}
+}
+
+// Auth tokens and users must reference each other.
+func (c *checker) checkAuth(tx kv.Tx) {
+	bt := tx.Bucket(bucket.AuthTokens)
+	bu := tx.Bucket(bucket.AuthUsers)
+	bt.ForEach(func(k, v []byte) error {
+		if u := bu.Get(v); u != nil && bytes.Equal(u[8:], k) {
+			return nil
+		}
+		token := append([]byte{}, k...)
+		c.add(v, bucket.AuthTokens, func(tx kv.Tx) error {
+			return tx.Bucket(bucket.AuthTokens).Delete(token)
+		}, "dangling auth token")
+		return nil
+	})
+	bu.ForEach(func(k, v []byte) error {
+		prefix := append([]byte{}, k...)
+		token := append([]byte{}, v[8:]...)
+		id := bt.Get(token)
+		if id == nil {
+			// The user already got the token, so keep it working
+			c.add(prefix, bucket.AuthTokens, func(tx kv.Tx) error {
+				return tx.Bucket(bucket.AuthTokens).Put(token, prefix)
+			}, "auth token of user is missing")
+		} else if !bytes.Equal(id, prefix) {
+			// A new token is generated when needed
+			c.add(prefix, bucket.AuthUsers, func(tx kv.Tx) error {
+				return tx.Bucket(bucket.AuthUsers).Delete(prefix)
+			}, "auth token belongs to user %d", btoi(id))
+		}
+		return nil
+	})
+}
+
+// Settings and the study state of a user must belong to a known user.
+// A user is known by a mode, a register date, phrases or trashed phrases.
+func (c *checker) checkOrphans(tx kv.Tx, phrases map[string]Phrase) {
+	users := map[string]bool{}
+	for k := range phrases {
+		users[k[:8]] = true
+	}
+	for _, b := range [][]byte{bucket.Modes, bucket.RegisterDates, bucket.Trash} {
+		tx.Bucket(b).ForEach(func(k, _ []byte) error {
+			users[string(k[:8])] = true
+			return nil
+		})
+	}
+	for _, b := range [][]byte{bucket.Settings, bucket.Undos, bucket.Hints, bucket.Streaks, bucket.Schedulers} {
+		b := b
+		tx.Bucket(b).ForEach(func(k, _ []byte) error {
+			if users[string(k)] {
+				return nil
+			}
+			prefix := append([]byte{}, k...)
+			c.add(prefix, b, func(tx kv.Tx) error {
+				return tx.Bucket(b).Delete(prefix)
+			}, "entry of unknown user")
+			return nil
+		})
+	}
+}
+
+// The ranks of each cohort must count the users by their scoretotals.
+// Cohorts that don't match are rebuilt from the scoretotals.
+func (c *checker) checkRanks(tx kv.Tx) {
+	expected, users := rankTree(tx)
+	actual := map[string]int64{}
+	tx.Bucket(bucket.Ranks).ForEach(func(k, v []byte) error {
+		actual[string(k)] = btoi(v)
+		return nil
+	})
+	broken := map[int64]bool{}
+	for k, n := range actual {
+		if expected[k] != n {
+			broken[btoi([]byte(k[:8]))] = true
+		}
+	}
+	for k, n := range expected {
+		if actual[k] != n {
+			broken[btoi([]byte(k[:8]))] = true
+		}
+	}
+	cohorts := make([]int64, 0, len(broken))
+	for cohort := range broken {
+		cohorts = append(cohorts, cohort)
+	}
+	sort.Slice(cohorts, func(i, j int) bool { return cohorts[i] < cohorts[j] })
+	for _, cohort := range cohorts {
+		cohort := cohort
+		fix := func(tx kv.Tx) error {
+			return rebuildRanks(tx, cohort)
+		}
+		ranked := countRanks(tx.Bucket(bucket.Ranks), cohort, rankMaxScore)
+		if ranked != users[cohort] {
+			c.add(nil, bucket.Ranks, fix, "%s: %d users ranked instead of %d", cohortName(cohort), ranked, users[cohort])
+		} else {
+			c.add(nil, bucket.Ranks, fix, "%s: users ranked with wrong scores", cohortName(cohort))
+		}
+	}
+}
+
+// Build the rank trees of all cohorts from the scoretotals.
+// Returns the nodes of the trees and the number of users in each cohort.
+func rankTree(tx kv.Tx) (map[string]int64, map[int64]int64) {
+	nodes := map[string]int64{}
+	users := map[int64]int64{}
+	tx.Bucket(bucket.Scoretotals).ForEach(func(k, v []byte) error {
+		for _, cohort := range rankCohorts(tx, k) {
+			users[cohort]++
+			for i := rankIndex(int(btoi(v))); i <= rankMaxScore+1; i += i & -i {
+				nodes[string(append(itob(cohort), itob(i)...))]++
+			}
+		}
+		return nil
+	})
+	return nodes, users
+}
+
+// Replace the rank tree of a cohort with the one built from the scoretotals.
+func rebuildRanks(tx kv.Tx, cohort int64) error {
+	b := tx.Bucket(bucket.Ranks)
+	prefix := itob(cohort)
+	var keys [][]byte
+	cur := b.Cursor()
+	for k, _ := cur.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cur.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	nodes, _ := rankTree(tx)
+	for k, n := range nodes {
+		if k[:8] == string(prefix) {
+			if err := b.Put([]byte(k), itob(n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Describe a rank cohort for humans.
+func cohortName(cohort int64) string {
+	if cohort == rankAll {
+		return "all users"
+	}
+	return fmt.Sprintf("users registered %d-%02d", (cohort-1)/12, (cohort-1)%12+1)
+}
+
+// Describe a card for humans.
+func cardName(card []byte) string {
+	pk, reverse := phraseKey(card)
+	name := fmt.Sprintf("phrase %d", btoi(pk[8:]))
+	if n := cardCloze(card); n > 0 {
+		return fmt.Sprintf("%s (cloze %d)", name, n)
+	}
+	if reverse {
+		return name + " (reverse)"
+	}
+	return name
+}
+
+// Get the keys of a map in order to find problems in a stable order.
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,145 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// GetChoices returns the current study together with options for a multiple choice study.
+// The options contain the correct answer and distractors drawn from the other phrases of the user.
+// Phrases sharing a tag with the studied phrase are preferred, then phrases of similar length.
+// Options are sorted alphabetically to not give away the correct answer.
+// If the user has no other phrases, there is only a single option.
+// If there is no study ready, the returned Study has a Total of 0 and there are no options.
+func (store Store) GetChoices(id int64) (Study, []string, error) {
+	study, err := store.GetStudy(id)
+	if err != nil || study.Total == 0 {
+		return study, nil, err
+	}
+
+	var candidates choiceCandidates
+
+	err = store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		key, _, _ := findCurrentStudy(tx, prefix, time.Now())
+		if key == nil {
+			return nil
+		}
+		pk, reverse := phraseKey(key)
+		current, err := getPhrase(tx, pk)
+		if err != nil {
+			return err
+		}
+
+		// Answers that cannot be used as distractors
+		taken := map[string]bool{strings.ToLower(study.Phrase): true}
+		for _, a := range study.Alternatives {
+			taken[strings.ToLower(a)] = true
+		}
+		length := utf8.RuneCountInString(study.Phrase)
+
+		c := tx.Bucket(bucket.Phrases).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if bytes.Equal(k, pk) {
+				continue
+			}
+			var p Phrase
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+				return fmt.Errorf("failed to decode phrase %x: %v", k, err)
+			}
+			for _, text := range choiceTexts(p, reverse, cardCloze(key) > 0) {
+				if taken[strings.ToLower(text)] {
+					continue
+				}
+				taken[strings.ToLower(text)] = true
+				diff := utf8.RuneCountInString(text) - length
+				if diff < 0 {
+					diff = -diff
+				}
+				candidates = append(candidates, choiceCandidate{text, sharesTag(current.Tags, p.Tags), diff})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return study, nil, fmt.Errorf("failed to get choices for %d: %v", id, err)
+	}
+
+	sort.Sort(candidates)
+	options := []string{study.Phrase}
+	for i := 0; i < len(candidates) && i < choiceDistractors; i++ {
+		options = append(options, candidates[i].text)
+	}
+	sort.Strings(options)
+	return study, options, nil
+}
+
+// Get the texts of a phrase that can be used as options for a study.
+// Cloze studies only use hidden words of other clozes.
+// Phrases with clozes are no options for forward studies, since they contain markup.
+func choiceTexts(p Phrase, reverse, cloze bool) []string {
+	numbers := clozeNumbers(p.Phrase)
+	switch {
+	case cloze:
+		var texts []string
+		for _, n := range numbers {
+			_, answer := renderCloze(p.Phrase, n)
+			texts = append(texts, answer)
+		}
+		return texts
+	case reverse:
+		return []string{p.Explanation}
+	case len(numbers) > 0:
+		return nil
+	default:
+		return []string{p.Phrase}
+	}
+}
+
+// A phrase that can be used as a wrong option in a multiple choice study.
+type choiceCandidate struct {
+	text      string
+	sharesTag bool
+	lenDiff   int
+}
+
+// Candidates sorted by how similar they are to the correct answer.
+type choiceCandidates []choiceCandidate
+
+func (c choiceCandidates) Len() int {
+	return len(c)
+}
+
+func (c choiceCandidates) Less(i, j int) bool {
+	if c[i].sharesTag != c[j].sharesTag {
+		return c[i].sharesTag
+	}
+	if c[i].lenDiff != c[j].lenDiff {
+		return c[i].lenDiff < c[j].lenDiff
+	}
+	return c[i].text < c[j].text
+}
+
+func (c choiceCandidates) Swap(i, j int) {
+	c[j], c[i] = c[i], c[j]
+}
+
+// Check if two lists of tags have at least one tag in common.
+func sharesTag(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package brain
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Clozes are parts of a phrase written as {{c1::word}}.
+// Each cloze number becomes a separate card
+// that shows the phrase with the cloze blanked and asks for the hidden words.
+// Clozes with the same number are hidden together.
+var clozePattern = regexp.MustCompile(`\{\{c(\d+)::(.+?)\}\}`)
+
+// Blank shown in place of the hidden cloze.
+const clozeBlank = "[...]"
+
+// Get the number of a cloze match.
+// Returns 0 for numbers that cannot be stored in a card key.
+func clozeNumber(m []string) int {
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 1 || n > clozeMask {
+		return 0
+	}
+	return n
+}
+
+// Get the sorted numbers of all clozes in a phrase.
+func clozeNumbers(phrase string) []int {
+	seen := map[int]bool{}
+	var numbers []int
+	for _, m := range clozePattern.FindAllStringSubmatch(phrase, -1) {
+		if n := clozeNumber(m); n > 0 && !seen[n] {
+			seen[n] = true
+			numbers = append(numbers, n)
+		}
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// Render the question and the answer for cloze n of a phrase.
+// Other clozes are shown as plain text.
+func renderCloze(phrase string, n int) (string, string) {
+	var answers []string
+	question := clozePattern.ReplaceAllStringFunc(phrase, func(s string) string {
+		m := clozePattern.FindStringSubmatch(s)
+		switch clozeNumber(m) {
+		case 0:
+			return s
+		case n:
+			answers = append(answers, m[2])
+			return clozeBlank
+		default:
+			return m[2]
+		}
+	})
+	return question, strings.Join(answers, ", ")
+}
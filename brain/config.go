@@ -7,11 +7,11 @@ const (
 	// somewhere between the new time and new time + studyTimeDiffusion*studyIntervals[i]
 	// to mix up the order in which words are studied.
 	studyTimeDiffusion = 0.2
-	// Maximum number of new studies at a time
+	// Default maximum number of new studies at a time
 	maxNewStudies = 20
 	// Delay scheduled new phrases are placed at
 	newStudyDelay = 24 * time.Hour
-	// Minimum number of studies needed to be due before notifying user
+	// Default minimum number of studies needed to be due before notifying user
 	dueMinCount = 10
 	// Time user has to be inactive before being notified
 	dueMinInactive = 1 * time.Hour
@@ -23,14 +23,49 @@ const (
 	payloadDuplicateInterval = 5 * time.Second
 	// Time after which message IDs are cleared, adjust to keep bucket size from exploding
 	messageIDmaxAge = 24 * time.Hour
-	// Hour of the day after which no notifications can be sent to the user
+	// Default hour of the day after which no notifications can be sent to the user
 	nightStart = 21
-	// Hour of the day from which on notifications can be sent to the user
+	// Default hour of the day from which on notifications can be sent to the user
 	nightEnd = 7
 	// Show user stats once a week
 	statInterval = 7 * 24 * time.Hour
+	// Range users can choose an interval multiplier from
+	minIntervalMultiplier = 0.1
+	maxIntervalMultiplier = 10
+	// Number of failed studies in a row after which a phrase is a leech
+	leechThreshold = 5
+	// Number of phrases EachPhrase reads in one transaction
+	eachPageSize = 100
+	// Number of wrong options shown in multiple choice studies
+	choiceDistractors = 3
+	// Studies with a longer pause in between belong to different sessions
+	sessionMaxPause = 30 * time.Minute
+	// Number of hardest phrases shown in a session summary
+	summaryHardest = 3
+	// Hour of the day users are reminded of their unmet daily goals
+	goalNudgeHour = 19
+	// Users with higher scores share the same rank
+	rankMaxScore = 1<<32 - 1
+	// Ease factor new phrases start with when using the SM-2 scheduler
+	sm2InitialEase = 2.5
+	// Ease factor never drops below this value to prevent phrases from being studied too often
+	sm2MinEase = 1.3
 )
 
+// Score updates for each grade.
+// The score of a phrase is used as index for studyIntervals and for zeroscores.
+var gradeScores = map[Grade]int{
+	GradeAgain: -2,
+	GradeHard:  0,
+	GradeGood:  1,
+	GradeEasy:  2,
+}
+
+// Default weights of FSRS v4, optimized on a large dataset of Anki reviews.
+var fsrsWeights = [17]float64{
+	0.4, 0.6, 2.4, 5.8, 4.93, 0.94, 0.86, 0.01, 1.49, 0.14, 0.94, 2.18, 0.05, 0.34, 1.26, 0.29, 2.61,
+}
+
 var studyIntervals = [21]time.Duration{
 	time.Hour,
 	8 * time.Hour,
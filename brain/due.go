@@ -0,0 +1,45 @@
+package brain
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Set the time a card is due to be studied.
+// The due index is kept in sync with the study times.
+func setStudytime(tx kv.Tx, card []byte, t int64) error {
+	bs := tx.Bucket(bucket.Studytimes)
+	bd := tx.Bucket(bucket.Dues)
+	if v := bs.Get(card); v != nil {
+		if err := bd.Delete(dueKey(card, btoi(v))); err != nil {
+			return err
+		}
+	}
+	if err := bs.Put(card, itob(t)); err != nil {
+		return err
+	}
+	return bd.Put(dueKey(card, t), []byte{})
+}
+
+// Remove a card from the study times and from the due index.
+func deleteStudytime(tx kv.Tx, card []byte) error {
+	bs := tx.Bucket(bucket.Studytimes)
+	v := bs.Get(card)
+	if v == nil {
+		return nil
+	}
+	if err := tx.Bucket(bucket.Dues).Delete(dueKey(card, btoi(v))); err != nil {
+		return err
+	}
+	return bs.Delete(card)
+}
+
+// Get the key of a card in the due index.
+func dueKey(card []byte, t int64) []byte {
+	return append(append(append([]byte{}, card[:8]...), itob(t)...), card[8:]...)
+}
+
+// Get the card of a key in the due index.
+func dueCard(k []byte) []byte {
+	return append(append([]byte{}, k[:8]...), k[16:]...)
+}
@@ -0,0 +1,70 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// FindExplanation returns a phrase of the user with the given explanation.
+// Explanations are compared ignoring case and whitespace.
+// Returns ErrNotFound if the user has no such phrase.
+func (store Store) FindExplanation(id int64, explanation string) (Phrase, error) {
+	var p Phrase
+	err := store.db.View(func(tx kv.Tx) error {
+		key := findExplanation(tx, itob(id), explanation)
+		if key == nil {
+			return ErrNotFound
+		}
+		var err error
+		p, err = getPhrase(tx, key)
+		return err
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to find explanation '%s' for %d: %v", explanation, id, err)
+	}
+	return p, err
+}
+
+// Get the key of a phrase with the given explanation from the index.
+// Returns nil if there is none.
+func findExplanation(tx kv.Tx, prefix []byte, explanation string) []byte {
+	ep := explanationKey(prefix, explanation, nil)
+	k, _ := tx.Bucket(bucket.Explanations).Cursor().Seek(ep)
+	if k == nil || !bytes.HasPrefix(k, ep) {
+		return nil
+	}
+	return append(append([]byte{}, prefix...), k[len(ep):]...)
+}
+
+// Update the explanations index for a phrase from prev to next explanation.
+// Pass an empty explanation to only add or only remove the phrase.
+func indexExplanation(tx kv.Tx, key []byte, prev, next string) error {
+	b := tx.Bucket(bucket.Explanations)
+	if prev != "" {
+		if err := b.Delete(explanationKey(key[:8], prev, key[8:])); err != nil {
+			return err
+		}
+	}
+	if next != "" {
+		return b.Put(explanationKey(key[:8], next, key[8:]), []byte{})
+	}
+	return nil
+}
+
+// Key in the explanations index is id+explanation+0+phrase.
+// Multiple phrases can have the same explanation, since phrases can be edited.
+// The explanation is normalized so that small differences are still detected as duplicates.
+func explanationKey(prefix []byte, explanation string, phraseID []byte) []byte {
+	k := append(append(append([]byte{}, prefix...), normExplanation(explanation)...), 0)
+	return append(k, phraseID...)
+}
+
+// Lower case explanation and collapse whitespace.
+// Zero bytes are removed, since they are used as separator in the index.
+func normExplanation(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.Replace(s, "\x00", "", -1)), " "))
+}
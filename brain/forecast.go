@@ -0,0 +1,92 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Forecast returns the expected study load of a user for the given number of days, starting today.
+// Days are calculated in the timezone of the user's cached profile.
+// Overdue studies are counted for today.
+// New phrases are expected to be introduced as soon as others are learned,
+// assuming the user learns all new phrases on the day they are studied first.
+// Today only new phrases that are due today free their slots.
+func (store Store) Forecast(id int64, days int) ([]ForecastDay, error) {
+	if days < 1 || days > MaxForecastDays {
+		return nil, fmt.Errorf("failed to get forecast for %d: days must be between 1 and %d", id, MaxForecastDays)
+	}
+	forecast := make([]ForecastDay, days)
+	err := store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		timezone := getTimezone(tx, prefix)
+		start := startOfNextDay(time.Now(), timezone).Add(-24 * time.Hour)
+		zone := time.FixedZone("", int(timezone*float64(time.Hour/time.Second)))
+		for i := range forecast {
+			forecast[i].Day = start.Add(time.Duration(i) * 24 * time.Hour).In(zone)
+		}
+
+		// Bucket scheduled studies per day, the due index is ordered by time
+		// New phrases due today free their slots for others today
+		learnedToday := 0
+		c := tx.Bucket(bucket.Dues).Cursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			i := int(time.Unix(btoi(k[8:16]), 0).Sub(start) / (24 * time.Hour))
+			if i >= days {
+				break
+			}
+			if isSuspended(tx, dueCard(k)) {
+				continue
+			}
+			if i < 0 {
+				i = 0
+			}
+			forecast[i].Due++
+			if i == 0 {
+				card := dueCard(k)
+				pk, _ := phraseKey(card)
+				p, err := getPhrase(tx, pk)
+				if err != nil {
+					return err
+				}
+				if score, _ := p.card(card); *score == 0 {
+					learnedToday++
+				}
+			}
+		}
+
+		// Count new phrases that can be scheduled
+		remaining := 0
+		v := tx.Bucket(bucket.NewPhrases).Get(prefix)
+		for o := 0; o+8 <= len(v); o += 8 {
+			if !isSuspended(tx, append(append([]byte{}, prefix...), v[o:o+8]...)) {
+				remaining++
+			}
+		}
+
+		// New phrases are scheduled when others are learned
+		settings, err := getSettings(tx, prefix)
+		if err != nil {
+			return err
+		}
+		for i := 0; i < days && remaining > 0; i++ {
+			n := settings.NewPhrases
+			if i == 0 && learnedToday < n {
+				n = learnedToday
+			}
+			if n > remaining {
+				n = remaining
+			}
+			forecast[i].New = n
+			remaining -= n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast for %d: %v", id, err)
+	}
+	return forecast, nil
+}
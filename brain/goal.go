@@ -0,0 +1,79 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// GetProgress returns how far a user got with the daily goals today.
+// The day starts at midnight in the given timezone.
+func (store Store) GetProgress(id int64, timezone float64) (Progress, error) {
+	var p Progress
+	err := store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		settings, err := getSettings(tx, prefix)
+		if err != nil {
+			return err
+		}
+		p = getProgress(tx, prefix, settings, time.Now(), timezone)
+		return nil
+	})
+	if err != nil {
+		return p, fmt.Errorf("failed to get progress for %d: %v", id, err)
+	}
+	return p, nil
+}
+
+// GetGoalNudgeTime gets the time until the user should be reminded of the daily goals.
+// This is the next goalNudgeHour in the given timezone.
+// If that is in the user's quiet hours, the nudge is delayed until they are over.
+// Returns ErrNotFound if the user hasn't set any goal
+// or if the quiet hours last until the next day, since the nudge would be for a day that is over.
+func (store Store) GetGoalNudgeTime(id int64, timezone float64) (time.Duration, error) {
+	var s Settings
+	err := store.db.View(func(tx kv.Tx) error {
+		var err error
+		s, err = getSettings(tx, itob(id))
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get goal nudge time for %d: %v", id, err)
+	}
+	if s.DailyStudies == 0 && s.DailyAdds == 0 {
+		return 0, ErrNotFound
+	}
+	now := time.Now()
+	dayEnd := startOfNextDay(now, timezone)
+	nudge := dayEnd.Add(-24*time.Hour + goalNudgeHour*time.Hour)
+	nudge = nudge.Add(quietDelay(nudge, timezone, s))
+	if !nudge.Before(dayEnd) {
+		return 0, ErrNotFound
+	}
+	if !nudge.After(now) {
+		nudge = nudge.Add(24 * time.Hour)
+	}
+	return nudge.Sub(now), nil
+}
+
+// Count studies and added phrases since the start of the day in the given timezone.
+func getProgress(tx kv.Tx, prefix []byte, s Settings, now time.Time, timezone float64) Progress {
+	p := Progress{StudyGoal: s.DailyStudies, AddGoal: s.DailyAdds}
+	since := startOfNextDay(now, timezone).Add(-24 * time.Hour).Unix()
+
+	c := tx.Bucket(bucket.Studies).Cursor()
+	for k, _ := c.Seek(append(append([]byte{}, prefix...), itob(since)...)); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		p.Studied++
+	}
+
+	c = tx.Bucket(bucket.PhraseAddTimes).Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		if btoi(v) >= since {
+			p.Added++
+		}
+	}
+	return p
+}
@@ -0,0 +1,46 @@
+package brain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// UseHint records that the user asked for a hint for the current study.
+// Returns the current study and the number of hints used for it, including this one.
+// If there is no study ready, the returned Study has a Total of 0.
+// Each hint reduces the score update of the study.
+func (store Store) UseHint(id int64) (Study, int, error) {
+	var hints int
+	study, err := store.GetStudy(id)
+	if err != nil || study.Total == 0 {
+		return study, 0, err
+	}
+	err = store.db.Update(func(tx kv.Tx) error {
+		prefix := itob(id)
+		key, _, _ := findCurrentStudy(tx, prefix, time.Now())
+		if key == nil {
+			return errors.New("no study found")
+		}
+		hints = getHints(tx, prefix, key) + 1
+		return tx.Bucket(bucket.Hints).Put(prefix, append(append([]byte{}, key[8:]...), itob(int64(hints))...))
+	})
+	if err != nil {
+		return study, 0, fmt.Errorf("failed to use hint for %d: %v", id, err)
+	}
+	return study, hints, nil
+}
+
+// Get the number of hints used for a card.
+// Hints of other cards don't count, they have been used for a study that is not current anymore.
+func getHints(tx kv.Tx, prefix, card []byte) int {
+	v := tx.Bucket(bucket.Hints).Get(prefix)
+	if v == nil || !bytes.Equal(v[:8], card[8:]) {
+		return 0
+	}
+	return int(btoi(v[8:]))
+}
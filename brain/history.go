@@ -0,0 +1,107 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// GetHistory returns all reviews of a phrase, oldest first,
+// and statistics derived from them.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) GetHistory(id int64, seq int) ([]Review, PhraseStats, error) {
+	var reviews []Review
+	var stats PhraseStats
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.View(func(tx kv.Tx) error {
+		if tx.Bucket(bucket.Phrases).Get(key) == nil {
+			return ErrNotFound
+		}
+		c := tx.Bucket(bucket.PhraseStudies).Cursor()
+		for k, v := c.Seek(key); k != nil && bytes.HasPrefix(k, key); k, v = c.Next() {
+			card := append(append([]byte{}, key[:8]...), v[:8]...)
+			r := Review{
+				Time:   time.Unix(btoi(k[16:24]), 0),
+				Score:  int(btoi(v[16:24])),
+				Failed: studyFailed(v),
+				Cloze:  cardCloze(card),
+			}
+			_, r.Reverse = phraseKey(card)
+			if len(v) >= 32 {
+				r.Grade = Grade(btoi(v[24:32]))
+			}
+			reviews = append(reviews, r)
+		}
+		return nil
+	})
+	if err != nil {
+		if err != ErrNotFound {
+			err = fmt.Errorf("failed to get history of phrase %d for %d: %v", seq, id, err)
+		}
+		return nil, stats, err
+	}
+	return reviews, phraseStats(reviews), nil
+}
+
+// Derive statistics from the reviews of a phrase in chronological order.
+func phraseStats(reviews []Review) PhraseStats {
+	type card struct {
+		reverse bool
+		cloze   int
+	}
+	var stats PhraseStats
+	scores := map[card]int{}
+	times := map[card]time.Time{}
+	var intervals int
+	var total time.Duration
+	for _, r := range reviews {
+		c := card{r.Reverse, r.Cloze}
+		if r.Failed && scores[c] > 0 {
+			stats.Lapses++
+		}
+		if !r.Failed {
+			stats.SuccessRate++
+		}
+		if t, ok := times[c]; ok {
+			intervals++
+			total += r.Time.Sub(t)
+		}
+		scores[c] = r.Score
+		times[c] = r.Time
+	}
+	stats.Reviews = len(reviews)
+	if stats.Reviews > 0 {
+		stats.SuccessRate /= float64(stats.Reviews)
+	}
+	if intervals > 0 {
+		stats.AverageInterval = total.Hours() / 24 / float64(intervals)
+	}
+	return stats
+}
+
+// Remove all studies of a phrase from the phrase studies index.
+// The studies themselves are kept for statistics.
+func deletePhraseStudies(tx kv.Tx, key []byte) error {
+	b := tx.Bucket(bucket.PhraseStudies)
+	var keys [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(key); k != nil && bytes.HasPrefix(k, key); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the key of a study in the phrase studies index.
+// studyID is the time and sequence of the study.
+func phraseStudyKey(card, studyID []byte) []byte {
+	pk, _ := phraseKey(card)
+	return append(pk, studyID...)
+}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // QueueImport stores phrases to be imported later.
@@ -17,13 +17,8 @@ import (
 func (store Store) QueueImport(id int64, phrases []Phrase) (int, error) {
 	prefix := itob(id)
 
-	err := store.db.Update(func(tx *bolt.Tx) error {
-		var err error
-		phrases, err = removeDuplicates(tx, prefix, phrases)
-		if err != nil {
-			return fmt.Errorf("remove duplicates for %d: %v", id, err)
-		}
-
+	err := store.db.Update(func(tx kv.Tx) error {
+		phrases = removeDuplicates(tx, prefix, phrases)
 		if len(phrases) == 0 {
 			return nil
 		}
@@ -49,7 +44,7 @@ func (store Store) QueueImport(id int64, phrases []Phrase) (int, error) {
 func (store Store) Import(id int64, phrases []Phrase) (int, error) {
 	count := 0
 
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		var err error
 		count, err = phraseImporter(tx, itob(id), phrases)
 		if err != nil {
@@ -62,12 +57,8 @@ func (store Store) Import(id int64, phrases []Phrase) (int, error) {
 	return count, err
 }
 
-func phraseImporter(tx *bolt.Tx, prefix []byte, phrases []Phrase) (int, error) {
-	ps, err := removeDuplicates(tx, prefix, phrases)
-	if err != nil {
-		return 0, err
-	}
-
+func phraseImporter(tx kv.Tx, prefix []byte, phrases []Phrase) (int, error) {
+	ps := removeDuplicates(tx, prefix, phrases)
 	now := time.Now()
 
 	for _, p := range ps {
@@ -79,25 +70,16 @@ func phraseImporter(tx *bolt.Tx, prefix []byte, phrases []Phrase) (int, error) {
 	return len(ps), addCountToBucket(tx.Bucket(bucket.Imports), prefix, 1)
 }
 
-// Go through existing phrases, find duplicates and remove them from phrases
-func removeDuplicates(tx *bolt.Tx, prefix []byte, phrases []Phrase) ([]Phrase, error) {
-	c := tx.Bucket(bucket.Phrases).Cursor()
-
-	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-		var e Phrase
-		if err := gob.NewDecoder(bytes.NewBuffer(v)).Decode(&e); err != nil {
-			return nil, fmt.Errorf("gob decode phrase at %#v: %v", k, err)
-		}
-
-		for i, p := range phrases {
-			if e.Explanation == p.Explanation {
-				phrases = append(phrases[:i], phrases[i+1:]...)
-				break
-			}
+// Remove phrases with explanations that exist already.
+// Existing explanations are looked up in the index.
+func removeDuplicates(tx kv.Tx, prefix []byte, phrases []Phrase) []Phrase {
+	var unique []Phrase
+	for _, p := range phrases {
+		if findExplanation(tx, prefix, p.Explanation) == nil {
+			unique = append(unique, p)
 		}
 	}
-
-	return phrases, nil
+	return unique
 }
 
 // ApplyImport adds phrases that have been previously queued with QueueImport().
@@ -106,7 +88,7 @@ func (store Store) ApplyImport(id int64) (int, error) {
 	prefix := itob(id)
 	var count int
 
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		bi := tx.Bucket(bucket.PendingImports)
 
 		var phrases []Phrase
@@ -135,7 +117,7 @@ func (store Store) ApplyImport(id int64) (int, error) {
 
 // ClearImport removes a queued import from the pending imports bucket.
 func (store Store) ClearImport(id int64) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		return tx.Bucket(bucket.PendingImports).Delete(itob(id))
 	})
 	if err != nil {
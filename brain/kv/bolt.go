@@ -0,0 +1,134 @@
+package kv
+
+import (
+	"io"
+	"os"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+)
+
+// OpenBolt opens the Bolt database at path and creates it if it doesn't exist.
+// Transactions of the returned DB also implement io.WriterTo
+// to write a consistent copy of the file.
+func OpenBolt(path string, mode os.FileMode) (DB, error) {
+	db, err := bolt.Open(path, mode, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return boltDB{db}, nil
+}
+
+type boltDB struct {
+	db *bolt.DB
+}
+
+// Translate Bolt errors to the errors of this package.
+func boltErr(err error) error {
+	switch err {
+	case bolt.ErrDatabaseNotOpen:
+		return ErrDatabaseNotOpen
+	case bolt.ErrTxNotWritable:
+		return ErrTxNotWritable
+	case bolt.ErrBucketNameRequired:
+		return ErrBucketNameRequired
+	case bolt.ErrBucketNotFound:
+		return ErrBucketNotFound
+	case bolt.ErrKeyRequired:
+		return ErrKeyRequired
+	}
+	return err
+}
+
+func (d boltDB) View(fn func(Tx) error) error {
+	return boltErr(d.db.View(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	}))
+}
+
+func (d boltDB) Update(fn func(Tx) error) error {
+	return boltErr(d.db.Update(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	}))
+}
+
+func (d boltDB) Batch(fn func(Tx) error) error {
+	return boltErr(d.db.Batch(func(tx *bolt.Tx) error {
+		return fn(boltTx{tx})
+	}))
+}
+
+func (d boltDB) Close() error {
+	return d.db.Close()
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Bucket(name []byte) Bucket {
+	b := t.tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return boltBucket{b}
+}
+
+func (t boltTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, boltErr(err)
+	}
+	return boltBucket{b}, nil
+}
+
+func (t boltTx) DeleteBucket(name []byte) error {
+	return boltErr(t.tx.DeleteBucket(name))
+}
+
+func (t boltTx) ForEach(fn func(name []byte, b Bucket) error) error {
+	return t.tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+		return fn(name, boltBucket{b})
+	})
+}
+
+func (t boltTx) Size() int64 {
+	return t.tx.Size()
+}
+
+func (t boltTx) WriteTo(w io.Writer) (int64, error) {
+	return t.tx.WriteTo(w)
+}
+
+type boltBucket struct {
+	b *bolt.Bucket
+}
+
+func (b boltBucket) Get(key []byte) []byte {
+	return b.b.Get(key)
+}
+
+func (b boltBucket) Put(key, value []byte) error {
+	return boltErr(b.b.Put(key, value))
+}
+
+func (b boltBucket) Delete(key []byte) error {
+	return boltErr(b.b.Delete(key))
+}
+
+func (b boltBucket) Cursor() Cursor {
+	return b.b.Cursor()
+}
+
+func (b boltBucket) ForEach(fn func(k, v []byte) error) error {
+	return b.b.ForEach(fn)
+}
+
+func (b boltBucket) NextSequence() (uint64, error) {
+	n, err := b.b.NextSequence()
+	return n, boltErr(err)
+}
+
+func (b boltBucket) KeyN() int {
+	return b.b.Stats().KeyN
+}
@@ -0,0 +1,90 @@
+// Package kv defines the key/value storage brain is written against.
+//
+// A DB consists of named buckets of sorted keys.
+// All access happens in transactions:
+// View transactions are read-only and Update transactions are writable.
+// An Update transaction is rolled back if its function returns an error.
+// Batch transactions are like Update transactions,
+// but concurrent ones are combined into a single transaction.
+// Keys and values returned by a transaction are only valid until it ends
+// and must not be modified.
+//
+// Two backends are available: OpenBolt stores data in a Bolt file
+// and NewMemory keeps everything in memory.
+package kv
+
+import "errors"
+
+var (
+	// ErrDatabaseNotOpen is returned when using a closed DB.
+	ErrDatabaseNotOpen = errors.New("database not open")
+	// ErrTxNotWritable is returned when writing in a read-only transaction.
+	ErrTxNotWritable = errors.New("tx not writable")
+	// ErrBucketNameRequired is returned when creating a bucket with an empty name.
+	ErrBucketNameRequired = errors.New("bucket name required")
+	// ErrBucketNotFound is returned when deleting a bucket that doesn't exist.
+	ErrBucketNotFound = errors.New("bucket not found")
+	// ErrKeyRequired is returned when putting an empty key.
+	ErrKeyRequired = errors.New("key required")
+)
+
+// DB is a key/value database.
+type DB interface {
+	// View runs fn in a read-only transaction.
+	View(fn func(Tx) error) error
+	// Update runs fn in a writable transaction.
+	Update(fn func(Tx) error) error
+	// Batch runs fn in a writable transaction that may be shared with concurrent calls to Batch.
+	// This reduces the number of disk syncs when many goroutines write at the same time.
+	// fn may be called more than once and must only change state through the transaction.
+	Batch(fn func(Tx) error) error
+	// Close releases all resources of the database.
+	Close() error
+}
+
+// Tx is a transaction.
+type Tx interface {
+	// Bucket returns the bucket with the given name or nil if it doesn't exist.
+	Bucket(name []byte) Bucket
+	// CreateBucketIfNotExists returns the bucket with the given name and creates it if needed.
+	CreateBucketIfNotExists(name []byte) (Bucket, error)
+	// DeleteBucket removes the bucket with the given name and all its keys.
+	DeleteBucket(name []byte) error
+	// ForEach calls fn for each bucket ordered by name and stops at the first error.
+	ForEach(fn func(name []byte, b Bucket) error) error
+	// Size returns the size of the database in bytes.
+	Size() int64
+}
+
+// Bucket is a collection of keys sorted byte-wise.
+type Bucket interface {
+	// Get returns the value of key or nil if it doesn't exist.
+	Get(key []byte) []byte
+	// Put sets the value of key.
+	Put(key, value []byte) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(key []byte) error
+	// Cursor returns a cursor to iterate the bucket in key order.
+	Cursor() Cursor
+	// ForEach calls fn for each key in order and stops at the first error.
+	ForEach(fn func(k, v []byte) error) error
+	// NextSequence returns an auto-incrementing integer, starting at 1.
+	NextSequence() (uint64, error)
+	// KeyN returns the number of keys.
+	KeyN() int
+}
+
+// Cursor iterates a bucket in key order.
+// All methods return a nil key once the cursor moved past the first or last key.
+type Cursor interface {
+	// First moves to the first key.
+	First() (k, v []byte)
+	// Last moves to the last key.
+	Last() (k, v []byte)
+	// Next moves to the next key.
+	Next() (k, v []byte)
+	// Prev moves to the previous key.
+	Prev() (k, v []byte)
+	// Seek moves to the first key greater than or equal to seek.
+	Seek(seek []byte) (k, v []byte)
+}
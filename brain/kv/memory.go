@@ -0,0 +1,297 @@
+package kv
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+// NewMemory returns an empty DB that keeps all data in memory.
+// Update transactions copy the buckets they write to
+// and only replace the originals once they succeed.
+// Data is lost once the DB is closed.
+func NewMemory() DB {
+	return &memDB{buckets: map[string]*memBucket{}}
+}
+
+type memDB struct {
+	mu      sync.RWMutex
+	buckets map[string]*memBucket
+}
+
+type memBucket struct {
+	entries []memEntry
+	seq     uint64
+}
+
+type memEntry struct {
+	key   []byte
+	value []byte
+}
+
+func (d *memDB) View(fn func(Tx) error) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.buckets == nil {
+		return ErrDatabaseNotOpen
+	}
+	return fn(&memTx{buckets: d.buckets})
+}
+
+func (d *memDB) Update(fn func(Tx) error) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.buckets == nil {
+		return ErrDatabaseNotOpen
+	}
+	tx := &memTx{
+		writable: true,
+		buckets:  make(map[string]*memBucket, len(d.buckets)),
+		copied:   map[string]bool{},
+	}
+	for name, b := range d.buckets {
+		tx.buckets[name] = b
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	d.buckets = tx.buckets
+	return nil
+}
+
+// Batch is the same as Update since there are no disk syncs to save.
+func (d *memDB) Batch(fn func(Tx) error) error {
+	return d.Update(fn)
+}
+
+func (d *memDB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.buckets = nil
+	return nil
+}
+
+type memTx struct {
+	writable bool
+	buckets  map[string]*memBucket
+	// Buckets already copied by a writable transaction
+	copied map[string]bool
+}
+
+func (t *memTx) Bucket(name []byte) Bucket {
+	if t.buckets[string(name)] == nil {
+		return nil
+	}
+	return memBucketTx{t, string(name)}
+}
+
+func (t *memTx) CreateBucketIfNotExists(name []byte) (Bucket, error) {
+	if !t.writable {
+		return nil, ErrTxNotWritable
+	}
+	if len(name) == 0 {
+		return nil, ErrBucketNameRequired
+	}
+	if t.buckets[string(name)] == nil {
+		t.buckets[string(name)] = &memBucket{}
+		t.copied[string(name)] = true
+	}
+	return t.Bucket(name), nil
+}
+
+func (t *memTx) DeleteBucket(name []byte) error {
+	if !t.writable {
+		return ErrTxNotWritable
+	}
+	if t.buckets[string(name)] == nil {
+		return ErrBucketNotFound
+	}
+	delete(t.buckets, string(name))
+	delete(t.copied, string(name))
+	return nil
+}
+
+func (t *memTx) ForEach(fn func(name []byte, b Bucket) error) error {
+	names := make([]string, 0, len(t.buckets))
+	for name := range t.buckets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := fn([]byte(name), t.Bucket([]byte(name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Size is the total length of all keys and values.
+func (t *memTx) Size() int64 {
+	var size int64
+	for _, b := range t.buckets {
+		for _, e := range b.entries {
+			size += int64(len(e.key) + len(e.value))
+		}
+	}
+	return size
+}
+
+// memBucketTx looks up its bucket in the transaction on each access,
+// so all handles of a bucket see the copy made by the first write.
+type memBucketTx struct {
+	tx   *memTx
+	name string
+}
+
+// The bucket as seen by the transaction.
+func (b memBucketTx) b() *memBucket {
+	return b.tx.buckets[b.name]
+}
+
+// Get the bucket for writing in a writable transaction.
+// It is copied on the first write of the transaction.
+func (b memBucketTx) write() *memBucket {
+	bucket := b.b()
+	if !b.tx.copied[b.name] {
+		bucket = &memBucket{
+			entries: append([]memEntry(nil), bucket.entries...),
+			seq:     bucket.seq,
+		}
+		b.tx.buckets[b.name] = bucket
+		b.tx.copied[b.name] = true
+	}
+	return bucket
+}
+
+// Index of the first entry with a key >= key.
+func (b memBucketTx) search(key []byte) int {
+	entries := b.b().entries
+	return sort.Search(len(entries), func(i int) bool {
+		return bytes.Compare(entries[i].key, key) >= 0
+	})
+}
+
+// Check if the entry at index i has the given key.
+func (b memBucketTx) has(i int, key []byte) bool {
+	entries := b.b().entries
+	return i < len(entries) && bytes.Equal(entries[i].key, key)
+}
+
+func (b memBucketTx) Get(key []byte) []byte {
+	i := b.search(key)
+	if b.has(i, key) {
+		return b.b().entries[i].value
+	}
+	return nil
+}
+
+func (b memBucketTx) Put(key, value []byte) error {
+	if !b.tx.writable {
+		return ErrTxNotWritable
+	}
+	if len(key) == 0 {
+		return ErrKeyRequired
+	}
+	bucket := b.write()
+	// Copy with exact capacity so appending to a returned value never changes the stored one
+	e := memEntry{append([]byte{}, key...), append([]byte{}, value...)}
+	i := b.search(key)
+	if b.has(i, key) {
+		bucket.entries[i] = e
+		return nil
+	}
+	bucket.entries = append(bucket.entries, memEntry{})
+	copy(bucket.entries[i+1:], bucket.entries[i:])
+	bucket.entries[i] = e
+	return nil
+}
+
+func (b memBucketTx) Delete(key []byte) error {
+	if !b.tx.writable {
+		return ErrTxNotWritable
+	}
+	// Deleting a missing key is no write
+	i := b.search(key)
+	if !b.has(i, key) {
+		return nil
+	}
+	bucket := b.write()
+	bucket.entries = append(bucket.entries[:i], bucket.entries[i+1:]...)
+	return nil
+}
+
+func (b memBucketTx) Cursor() Cursor {
+	return &memCursor{b: b}
+}
+
+func (b memBucketTx) ForEach(fn func(k, v []byte) error) error {
+	c := b.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b memBucketTx) NextSequence() (uint64, error) {
+	if !b.tx.writable {
+		return 0, ErrTxNotWritable
+	}
+	bucket := b.write()
+	bucket.seq++
+	return bucket.seq, nil
+}
+
+func (b memBucketTx) KeyN() int {
+	return len(b.b().entries)
+}
+
+// memCursor remembers the last key instead of a position.
+// Each move searches the bucket again,
+// which keeps iterating safe while the bucket is modified.
+type memCursor struct {
+	b   memBucketTx
+	key []byte
+}
+
+func (c *memCursor) at(i int) ([]byte, []byte) {
+	entries := c.b.b().entries
+	if i < 0 || i >= len(entries) {
+		c.key = nil
+		return nil, nil
+	}
+	e := entries[i]
+	c.key = e.key
+	return e.key, e.value
+}
+
+func (c *memCursor) First() ([]byte, []byte) {
+	return c.at(0)
+}
+
+func (c *memCursor) Last() ([]byte, []byte) {
+	return c.at(len(c.b.b().entries) - 1)
+}
+
+func (c *memCursor) Next() ([]byte, []byte) {
+	if c.key == nil {
+		return nil, nil
+	}
+	i := c.b.search(c.key)
+	if c.b.has(i, c.key) {
+		i++
+	}
+	return c.at(i)
+}
+
+func (c *memCursor) Prev() ([]byte, []byte) {
+	if c.key == nil {
+		return nil, nil
+	}
+	return c.at(c.b.search(c.key) - 1)
+}
+
+func (c *memCursor) Seek(seek []byte) ([]byte, []byte) {
+	return c.at(c.b.search(seek))
+}
@@ -0,0 +1,74 @@
+package brain
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+	"github.com/jorinvo/slangbrain/brain/migration"
+)
+
+var (
+	keyVersion = []byte("version")
+	errDryRun  = errors.New("dry run")
+)
+
+// Migrate applies all pending migrations of db in a single transaction.
+// Each migration and the changes it made are logged.
+// With dryRun the transaction is rolled back once all migrations ran.
+// Returns the schema version before and after migrating.
+func Migrate(db kv.DB, dryRun bool, logger *log.Logger) (int, int, error) {
+	var from int
+	err := db.Update(func(tx kv.Tx) error {
+		var err error
+		if from, err = migrate(tx, logger); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errDryRun {
+		return from, from, err
+	}
+	return from, len(migration.All), nil
+}
+
+// Get the current schema version of the database.
+func getVersion(tx kv.Tx) int {
+	if b := tx.Bucket(bucket.Meta); b != nil {
+		if v := b.Get(keyVersion); v != nil {
+			return int(btoi(v))
+		}
+	}
+	return migration.Detect(tx)
+}
+
+// Apply pending migrations and store the new version.
+// Returns the version before migrating.
+func migrate(tx kv.Tx, logger *log.Logger) (int, error) {
+	version := getVersion(tx)
+	if version > len(migration.All) {
+		return version, fmt.Errorf("database version %d is newer than supported version %d", version, len(migration.All))
+	}
+
+	for _, m := range migration.All[version:] {
+		name := m.Name
+		logger.Printf("migration %s", name)
+		logf := func(format string, args ...interface{}) {
+			logger.Printf(name+": "+format, args...)
+		}
+		if err := m.Up(tx, logf); err != nil {
+			return version, fmt.Errorf("failed migration %s: %v", name, err)
+		}
+	}
+
+	b, err := tx.CreateBucketIfNotExists(bucket.Meta)
+	if err != nil {
+		return version, err
+	}
+	return version, b.Put(keyVersion, itob(int64(len(migration.All))))
+}
@@ -0,0 +1,138 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Update storage of all integers to be stored as big endian instead of varint.
+// This makes the bytes sortable.
+//
+// - Go through all buckets and read data in memory
+// - Delete old keys
+// - Update data
+// - Write new data
+func numToBigEndian(tx kv.Tx, logf Logf) error {
+	keyValUpdater := func(k, v []byte) ([]byte, []byte, error) {
+		ik, err := oldbtoi(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		iv, err := oldbtoi(v)
+		if err != nil {
+			return nil, nil, err
+		}
+		return itob(ik), itob(iv), nil
+	}
+	keyUpdater := func(k, v []byte) ([]byte, []byte, error) {
+		ik, err := oldbtoi(k)
+		if err != nil {
+			return nil, nil, err
+		}
+		return itob(ik), v, nil
+	}
+	// Keys of phrases are id and sequence, each using 8 bytes
+	phraseKey := func(k []byte) ([]byte, error) {
+		id, err := oldbtoi(k[:8])
+		if err != nil {
+			return nil, err
+		}
+		seq, err := oldbtoi(k[8:])
+		if err != nil {
+			return nil, err
+		}
+		return append(itob(id), itob(seq)...), nil
+	}
+
+	buckets := []struct {
+		bucket  []byte
+		updater func(k, v []byte) ([]byte, []byte, error)
+	}{
+		{
+			bucket:  bucket.Modes,
+			updater: keyValUpdater,
+		},
+		{
+			bucket: bucket.Phrases,
+			updater: func(k, v []byte) ([]byte, []byte, error) {
+				nk, err := phraseKey(k)
+				return nk, v, err
+			},
+		},
+		{
+			bucket: bucket.Studytimes,
+			updater: func(k, v []byte) ([]byte, []byte, error) {
+				nk, err := phraseKey(k)
+				if err != nil {
+					return nil, nil, err
+				}
+				iv, err := oldbtoi(v)
+				if err != nil {
+					return nil, nil, err
+				}
+				return nk, itob(iv), nil
+			},
+		},
+		{
+			bucket:  bucket.Reads,
+			updater: keyValUpdater,
+		},
+		{
+			bucket:  bucket.Activities,
+			updater: keyValUpdater,
+		},
+		{
+			bucket:  bucket.Subscriptions,
+			updater: keyUpdater,
+		},
+		{
+			bucket:  bucket.Profiles,
+			updater: keyUpdater,
+		},
+		{
+			bucket:  bucket.RegisterDates,
+			updater: keyValUpdater,
+		},
+	}
+
+	for _, bu := range buckets {
+		// Collect and update data
+		b := tx.Bucket(bu.bucket)
+		if b == nil {
+			logf("no bucket: %s", bu.bucket)
+			continue
+		}
+		var oldKeys [][]byte
+		data := map[string][]byte{}
+		err := b.ForEach(func(k []byte, v []byte) error {
+			nk, nv, err := bu.updater(k, v)
+			oldKeys = append(oldKeys, append([]byte{}, k...))
+			data[string(nk)] = append([]byte{}, nv...)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		// Delete old keys
+		for _, k := range oldKeys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		// Write data to bucket
+		for k, v := range data {
+			if err := b.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		logf("converted %d keys of %s", len(data), bu.bucket)
+	}
+	return nil
+}
+
+func oldbtoi(b []byte) (int64, error) {
+	return binary.ReadVarint(bytes.NewBuffer(b))
+}
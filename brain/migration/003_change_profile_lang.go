@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"bytes"
+	"encoding/gob"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+type profileData struct {
+	Name      string
+	Locale    string
+	Timezone  float64
+	CacheTime time.Time
+}
+
+// All users at the time spoke German.
+func changeProfileLang(tx kv.Tx, logf Logf) error {
+	b := tx.Bucket(bucket.Profiles)
+	if b == nil {
+		return nil
+	}
+	updates := map[string][]byte{}
+	err := b.ForEach(func(k, v []byte) error {
+		var p profileData
+		// Read profile
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+			return err
+		}
+		// Change language
+		p.Locale = "de_DE"
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+			return err
+		}
+		updates[string(k)] = buf.Bytes()
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Write back
+	for k, v := range updates {
+		if err := b.Put([]byte(k), v); err != nil {
+			return err
+		}
+	}
+	logf("changed locale of %d profiles", len(updates))
+	return nil
+}
@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Scheduling as it was at the time.
+const (
+	studyTimeDiffusion = 30
+	newPerDay          = 30.0
+)
+
+var studyIntervals = [14]time.Duration{
+	2 * time.Hour,
+	8 * time.Hour,
+	20 * time.Hour,
+	44 * time.Hour,
+	(4*24 - 2) * time.Hour,
+	(7*24 - 2) * time.Hour,
+	(14*24 - 2) * time.Hour,
+	(30*24 - 2) * time.Hour,
+	(60*24 - 2) * time.Hour,
+	(100*24 - 2) * time.Hour,
+	(5*30*24 - 2) * time.Hour,
+	(8*30*24 - 2) * time.Hour,
+	(12*30*24 - 2) * time.Hour,
+	(15*30*24 - 2) * time.Hour,
+}
+
+// To simplify the calulation of the offset used when calculating studytimes
+// we introduced a new bucket zeroscores.
+//
+// The bucket is updated at every place where we change the score of a phrase,
+// but with the migration we update the bucket for all existing phrases.
+//
+// Additionally, all studytimes are reset to times calculated with the new algorithm.
+func zeroscoresStudyreset(tx kv.Tx, logf Logf) error {
+	bz, err := tx.CreateBucketIfNotExists(bucket.Zeroscores)
+	if err != nil {
+		return err
+	}
+	bs, err := tx.CreateBucketIfNotExists(bucket.Studytimes)
+	if err != nil {
+		return err
+	}
+	bp := tx.Bucket(bucket.Phrases)
+	if bp == nil {
+		return nil
+	}
+
+	now := time.Now()
+	count := 0
+	err = bp.ForEach(func(k []byte, v []byte) error {
+		prefix := k[:8]
+
+		p, err := decodePhrase(v)
+		if err != nil {
+			return err
+		}
+
+		// Update study time
+		i := p.Score
+		if i < 0 {
+			i = 0
+		}
+		if i >= len(studyIntervals) {
+			i = len(studyIntervals) - 1
+		}
+		var zeroScores float64
+		if v := bz.Get(prefix); v != nil {
+			zeroScores = float64(btoi(v))
+		}
+		offset := time.Duration(zeroScores/newPerDay*24) * time.Hour
+		diffusion := time.Duration(rand.Intn(studyTimeDiffusion)) * time.Minute
+		next := studyIntervals[i] + offset + diffusion
+		if err := bs.Put(k, itob(now.Add(next).Unix())); err != nil {
+			return err
+		}
+		count++
+
+		// Update zeroscore
+		if p.Score != 0 {
+			return nil
+		}
+		var zs int64
+		if v := bz.Get(prefix); v != nil {
+			zs = btoi(v)
+		}
+		return bz.Put(prefix, itob(zs+1))
+	})
+	logf("rescheduled %d phrases", count)
+	return err
+}
@@ -0,0 +1,105 @@
+package migration
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+const maxNewStudies = 30
+
+// Only schedule a limited number of new phrases
+// and keep the rest in the new phrases bucket.
+func bucketNewphrases(tx kv.Tx, logf Logf) error {
+	bn, err := tx.CreateBucketIfNotExists(bucket.NewPhrases)
+	if err != nil {
+		return err
+	}
+	bs, err := tx.CreateBucketIfNotExists(bucket.Studytimes)
+	if err != nil {
+		return err
+	}
+	bz, err := tx.CreateBucketIfNotExists(bucket.Zeroscores)
+	if err != nil {
+		return err
+	}
+	bp := tx.Bucket(bucket.Phrases)
+	br := tx.Bucket(bucket.Reads)
+	if bp == nil || br == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	// For each phrase, for each user
+	return br.ForEach(func(prefix, _ []byte) error {
+		c := bp.Cursor()
+		i := 0
+		pc := 0
+		var newPhrases []byte
+		// Collect changes first, since updating a bucket while iterating it is unsafe
+		scheduled := map[string]bool{}
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			p, err := decodePhrase(v)
+			if err != nil {
+				return err
+			}
+
+			// Count phrases for logging
+			pc++
+
+			if p.Score > 0 {
+				continue
+			}
+
+			i++
+
+			// Reschedule scheduled zero studies
+			if i < maxNewStudies {
+				scheduled[string(k)] = true
+				continue
+			}
+
+			// Collect new phrases
+			newPhrases = append(newPhrases, k[8:]...)
+			scheduled[string(k)] = false
+		}
+
+		next := itob(now.Add(2 * time.Hour).Unix())
+		for k, ok := range scheduled {
+			if ok {
+				err = bs.Put([]byte(k), next)
+			} else {
+				// Delete study time of phrase
+				err = bs.Delete([]byte(k))
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		// Save new phrases to bucket
+		if err := bn.Put(prefix, newPhrases); err != nil {
+			return err
+		}
+
+		// Update zeroscores bucket
+		var zeroscore int64
+		if v := bz.Get(prefix); v != nil {
+			zeroscore = btoi(v)
+		}
+
+		logf("%v: total: %4d, new: %4d, zeroscore: %3d actualzeros: %3d", btoi(prefix), pc, len(newPhrases)/8, zeroscore, i)
+
+		if zeroscore > maxNewStudies {
+			zeroscore = maxNewStudies
+		}
+		if zeroscore > int64(i) {
+			zeroscore = int64(i)
+		}
+
+		return bz.Put(prefix, itob(zeroscore))
+	})
+}
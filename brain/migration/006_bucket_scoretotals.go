@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Sum the scores of each user into the new scoretotals bucket.
+func bucketScoretotals(tx kv.Tx, logf Logf) error {
+	b, err := tx.CreateBucketIfNotExists(bucket.Scoretotals)
+	if err != nil {
+		return err
+	}
+	bp := tx.Bucket(bucket.Phrases)
+	if bp == nil {
+		return nil
+	}
+
+	totals := map[int64]int{}
+
+	// Sum scores per user
+	err = bp.ForEach(func(k, v []byte) error {
+		p, err := decodePhrase(v)
+		if err != nil {
+			return err
+		}
+		totals[btoi(k)] += p.Score
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Write scoretotals to bucket
+	for k, v := range totals {
+		if err := b.Put(itob(k), itob(int64(v))); err != nil {
+			return err
+		}
+	}
+	logf("summed scores of %d users", len(totals))
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Register all existing users with the current time.
+func registerUsers(tx kv.Tx, logf Logf) error {
+	br, err := tx.CreateBucketIfNotExists(bucket.RegisterDates)
+	if err != nil {
+		return err
+	}
+	bm := tx.Bucket(bucket.Modes)
+	if bm == nil {
+		return nil
+	}
+
+	now := itob(time.Now().Unix())
+	var ids [][]byte
+	err = bm.ForEach(func(k, _ []byte) error {
+		if br.Get(k) == nil {
+			ids = append(ids, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range ids {
+		logf("register %d", btoi(k))
+		if err := br.Put(k, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Zeroscores are reset from scratch in 010.
+func emptyZeroscores(tx kv.Tx, logf Logf) error {
+	if tx.Bucket(bucket.Zeroscores) == nil {
+		return nil
+	}
+	logf("deleted bucket %s", bucket.Zeroscores)
+	return tx.DeleteBucket(bucket.Zeroscores)
+}
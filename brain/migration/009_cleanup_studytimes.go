@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Remove data of deleted phrases and add missing add times.
+func cleanupStudytimes(tx kv.Tx, logf Logf) error {
+	bp := tx.Bucket(bucket.Phrases)
+	if bp == nil {
+		return nil
+	}
+	var buckets []kv.Bucket
+	for _, name := range [][]byte{bucket.Studytimes, bucket.PhraseAddTimes, bucket.NewPhrases} {
+		b, err := tx.CreateBucketIfNotExists(name)
+		if err != nil {
+			return err
+		}
+		buckets = append(buckets, b)
+	}
+	bs, ba, bn := buckets[0], buckets[1], buckets[2]
+
+	now := itob(time.Now().Unix())
+
+	// Collect changes first, since updating a bucket while iterating it is unsafe
+	var removeStudies, removeNew [][]byte
+	err := bs.ForEach(func(k, _ []byte) error {
+		key := append([]byte{}, k...)
+		// Remove studytime for non-existend phrases
+		if bp.Get(k) == nil {
+			removeStudies = append(removeStudies, key)
+			return nil
+		}
+		// Remove phrases from new phrases if they are already scheduled for studying
+		if bn.Get(k) != nil {
+			removeNew = append(removeNew, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range removeStudies {
+		m := ""
+		if ba.Get(k) != nil {
+			m = "and add time "
+			if err := ba.Delete(k); err != nil {
+				return err
+			}
+		}
+		logf("remove study %sfor key %x (id %d, seq %d)", m, k, btoi(k[:8]), btoi(k[8:]))
+		if err := bs.Delete(k); err != nil {
+			return err
+		}
+	}
+	for _, k := range removeNew {
+		logf("remove scheduled phrase from new phrases: key %x (id %d, seq %d)", k, btoi(k[:8]), btoi(k[8:]))
+		if err := bn.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	// Add missing add times
+	var missing [][]byte
+	err = bp.ForEach(func(k, _ []byte) error {
+		if ba.Get(k) == nil {
+			missing = append(missing, append([]byte{}, k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range missing {
+		logf("add missing add time for key %x (id %d, seq %d)", k, btoi(k[:8]), btoi(k[8:]))
+		if err := ba.Put(k, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
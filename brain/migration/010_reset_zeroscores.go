@@ -0,0 +1,43 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Reset zeroscore for each user to the count of all phrases which are currently being studied and have a score of 0.
+func resetZeroscores(tx kv.Tx, logf Logf) error {
+	bz, err := tx.CreateBucketIfNotExists(bucket.Zeroscores)
+	if err != nil {
+		return err
+	}
+	bp := tx.Bucket(bucket.Phrases)
+	bs := tx.Bucket(bucket.Studytimes)
+	if bp == nil || bs == nil {
+		return nil
+	}
+
+	scores := map[int64]int64{}
+	err = bs.ForEach(func(k, _ []byte) error {
+		p, err := decodePhrase(bp.Get(k))
+		if err != nil {
+			return err
+		}
+		// Update zeroscore
+		if p.Score == 0 {
+			scores[btoi(k[:8])]++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range scores {
+		logf("id: %d; zeroscore: %d", k, v)
+		if err := bz.Put(itob(k), itob(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
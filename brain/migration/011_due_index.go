@@ -0,0 +1,27 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Build the due index, which orders the study times of each user by time.
+func dueIndex(tx kv.Tx, logf Logf) error {
+	bd, err := resetBucket(tx, bucket.Dues)
+	if err != nil {
+		return err
+	}
+	bs := tx.Bucket(bucket.Studytimes)
+	if bs == nil {
+		return nil
+	}
+
+	count := 0
+	err = bs.ForEach(func(k, v []byte) error {
+		count++
+		// id+time+phrase
+		return bd.Put(append(append(append([]byte{}, k[:8]...), v...), k[8:]...), []byte{})
+	})
+	logf("indexed %d study times", count)
+	return err
+}
@@ -0,0 +1,39 @@
+package migration
+
+import (
+	"strings"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Build the explanations index, which is used to find duplicate phrases.
+func explanationIndex(tx kv.Tx, logf Logf) error {
+	be, err := resetBucket(tx, bucket.Explanations)
+	if err != nil {
+		return err
+	}
+	bp := tx.Bucket(bucket.Phrases)
+	if bp == nil {
+		return nil
+	}
+
+	count := 0
+	err = bp.ForEach(func(k, v []byte) error {
+		p, err := decodePhrase(v)
+		if err != nil {
+			return err
+		}
+		count++
+		// id+explanation+0+phrase
+		key := append(append(append([]byte{}, k[:8]...), normExplanation(p.Explanation)...), 0)
+		return be.Put(append(key, k[8:]...), []byte{})
+	})
+	logf("indexed %d explanations", count)
+	return err
+}
+
+// Same as in brain.
+func normExplanation(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(strings.Replace(s, "\x00", "", -1)), " "))
+}
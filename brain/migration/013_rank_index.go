@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Same as in brain.
+const rankMaxScore = 1<<32 - 1
+
+// Build the rank index, which counts users by score total for all users and for each register month.
+func rankIndex(tx kv.Tx, logf Logf) error {
+	br, err := resetBucket(tx, bucket.Ranks)
+	if err != nil {
+		return err
+	}
+	bt := tx.Bucket(bucket.Scoretotals)
+	if bt == nil {
+		return nil
+	}
+
+	bd := tx.Bucket(bucket.RegisterDates)
+	count := 0
+	err = bt.ForEach(func(k, v []byte) error {
+		count++
+		score := btoi(v)
+		// All users
+		cohorts := []int64{0}
+		if bd != nil {
+			if d := bd.Get(k); d != nil {
+				y, m, _ := time.Unix(btoi(d), 0).UTC().Date()
+				cohorts = append(cohorts, int64(y)*12+int64(m))
+			}
+		}
+		for _, cohort := range cohorts {
+			if err := addRank(br, cohort, score); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	logf("ranked %d users", count)
+	return err
+}
+
+// Add a user with the given score to the Fenwick tree of a cohort.
+func addRank(b kv.Bucket, cohort, score int64) error {
+	if score > rankMaxScore {
+		score = rankMaxScore
+	}
+	for i := score + 1; i <= rankMaxScore+1; i += i & -i {
+		k := append(itob(cohort), itob(i)...)
+		n := int64(1)
+		if v := b.Get(k); v != nil {
+			n += btoi(v)
+		}
+		if err := b.Put(k, itob(n)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Build the phrase studies index, which is used to get the history of a phrase.
+// Each entry holds a copy of the study.
+// Only studies of existing phrases are indexed.
+func phraseStudyIndex(tx kv.Tx, logf Logf) error {
+	bi, err := resetBucket(tx, bucket.PhraseStudies)
+	if err != nil {
+		return err
+	}
+	bs := tx.Bucket(bucket.Studies)
+	bp := tx.Bucket(bucket.Phrases)
+	if bs == nil || bp == nil {
+		return nil
+	}
+
+	count := 0
+	err = bs.ForEach(func(k, v []byte) error {
+		// The highest byte of a card marks reverse and cloze cards of the phrase
+		key := append(append([]byte{}, k[:8]...), v[:8]...)
+		key[8] = 0
+		if bp.Get(key) == nil {
+			return nil
+		}
+		count++
+		// id+phrase+time
+		return bi.Put(append(key, k[8:]...), append([]byte{}, v...))
+	})
+	logf("indexed %d studies", count)
+	return err
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Add a sequence to the keys of studies, so studies in the same second don't overwrite each other.
+// Undos refer to the last study by its key and are updated as well.
+// The phrase studies index is rebuilt for the new keys.
+func studySequence(tx kv.Tx, logf Logf) error {
+	bs := tx.Bucket(bucket.Studies)
+	if bs == nil {
+		return nil
+	}
+
+	// Collect studies first, since updating a bucket while iterating it is unsafe
+	var keys, values [][]byte
+	err := bs.ForEach(func(k, v []byte) error {
+		// id+time
+		if len(k) == 16 {
+			keys = append(keys, append([]byte{}, k...))
+			values = append(values, append([]byte{}, v...))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	seqs := map[string][]byte{}
+	for i, k := range keys {
+		seq, err := bs.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := bs.Delete(k); err != nil {
+			return err
+		}
+		seqs[string(k)] = itob(int64(seq))
+		if err := bs.Put(append(append([]byte{}, k...), seqs[string(k)]...), values[i]); err != nil {
+			return err
+		}
+	}
+	logf("added sequence to %d studies", len(keys))
+
+	if bu := tx.Bucket(bucket.Undos); bu != nil {
+		undos := map[string][]byte{}
+		err := bu.ForEach(func(k, v []byte) error {
+			undos[string(k)] = append([]byte{}, v...)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for k, v := range undos {
+			// time+phrase+score+time+gob(Memory)
+			seq, ok := seqs[k+string(v[:8])]
+			if !ok {
+				if err := bu.Delete([]byte(k)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := bu.Put([]byte(k), append(append(append([]byte{}, v[:8]...), seq...), v[8:]...)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return phraseStudyIndex(tx, logf)
+}
@@ -0,0 +1,32 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Add the previous leech flag to undos, so undoing a study can revert a phrase becoming a leech.
+// Existing undos are assumed to not have been leeches before.
+func undoLeech(tx kv.Tx, logf Logf) error {
+	bu := tx.Bucket(bucket.Undos)
+	if bu == nil {
+		return nil
+	}
+
+	undos := map[string][]byte{}
+	err := bu.ForEach(func(k, v []byte) error {
+		undos[string(k)] = append([]byte{}, v...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for k, v := range undos {
+		// time+seq+phrase+score+time+gob(Memory)
+		if err := bu.Put([]byte(k), append(append(append([]byte{}, v[:40]...), itob(0)...), v[40:]...)); err != nil {
+			return err
+		}
+	}
+	logf("added leech flag to %d undos", len(undos))
+	return nil
+}
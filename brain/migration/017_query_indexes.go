@@ -0,0 +1,49 @@
+package migration
+
+import (
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Build the indexes phrase queries walk to get phrases sorted by add time or score.
+func queryIndexes(tx kv.Tx, logf Logf) error {
+	ba, err := resetBucket(tx, bucket.AddedPhrases)
+	if err != nil {
+		return err
+	}
+	bs, err := resetBucket(tx, bucket.ScorePhrases)
+	if err != nil {
+		return err
+	}
+
+	if bt := tx.Bucket(bucket.PhraseAddTimes); bt != nil {
+		count := 0
+		err := bt.ForEach(func(k, v []byte) error {
+			count++
+			// id+time+phrase
+			return ba.Put(append(append(append([]byte{}, k[:8]...), v...), k[8:]...), []byte{})
+		})
+		if err != nil {
+			return err
+		}
+		logf("indexed %d add times", count)
+	}
+
+	if bp := tx.Bucket(bucket.Phrases); bp != nil {
+		count := 0
+		err := bp.ForEach(func(k, v []byte) error {
+			p, err := decodePhrase(v)
+			if err != nil {
+				return err
+			}
+			count++
+			// id+score+phrase
+			return bs.Put(append(append(append([]byte{}, k[:8]...), itob(int64(p.Score))...), k[8:]...), []byte{})
+		})
+		if err != nil {
+			return err
+		}
+		logf("indexed %d scores", count)
+	}
+	return nil
+}
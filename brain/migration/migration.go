@@ -0,0 +1,108 @@
+// Package migration contains all changes to the schema of the database.
+//
+// The schema version of a database is the number of applied migrations.
+// brain applies pending migrations in the order of All when opening a database.
+// Migrations must not use types of brain, since these change over time.
+// Instead each migration keeps copies of the data as it was at the time.
+package migration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Logf reports a change made by a migration.
+type Logf func(format string, args ...interface{})
+
+// Migration changes a database from one schema version to the next.
+type Migration struct {
+	Name string
+	Up   func(tx kv.Tx, logf Logf) error
+}
+
+// All migrations in the order they are applied.
+// Append new migrations to the end and never change existing ones.
+var All = []Migration{
+	{"001_phrases_json_to_gob", phrasesJSONToGob},
+	{"002_num_to_big_endian", numToBigEndian},
+	{"003_change_profile_lang", changeProfileLang},
+	{"004_zeroscores_studyreset", zeroscoresStudyreset},
+	{"005_bucket_newphrases", bucketNewphrases},
+	{"006_bucket_scoretotals", bucketScoretotals},
+	{"007_register_users", registerUsers},
+	{"008_empty_zeroscores", emptyZeroscores},
+	{"009_cleanup_studytimes", cleanupStudytimes},
+	{"010_reset_zeroscores", resetZeroscores},
+	{"011_due_index", dueIndex},
+	{"012_explanation_index", explanationIndex},
+	{"013_rank_index", rankIndex},
+	{"014_phrase_study_index", phraseStudyIndex},
+	{"015_study_sequence", studySequence},
+	{"016_undo_leech", undoLeech},
+	{"017_query_indexes", queryIndexes},
+}
+
+// Legacy is the version of databases created before the version was stored.
+// Migrations up to Legacy had been applied by hand.
+const Legacy = 10
+
+// Detect returns the schema version of a database without a stored version.
+// A database without phrases is new and doesn't need any migrations.
+// Phrases stored as JSON or with varint keys are detected,
+// since backups that old are still around.
+// Everything else is assumed to be at Legacy.
+func Detect(tx kv.Tx) int {
+	b := tx.Bucket(bucket.Phrases)
+	if b == nil {
+		return len(All)
+	}
+	version := Legacy
+	b.ForEach(func(k, v []byte) error {
+		if json.Valid(v) {
+			version = 0
+		} else if k[0] != 0 && version > 1 {
+			// Chat IDs are too small to use the first byte in big endian
+			version = 1
+		}
+		return nil
+	})
+	return version
+}
+
+// The fields of a phrase used by migrations.
+type phrase struct {
+	Phrase      string
+	Explanation string
+	Score       int
+}
+
+func decodePhrase(v []byte) (phrase, error) {
+	var p phrase
+	err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p)
+	return p, err
+}
+
+// Rebuild an index bucket from scratch in case it is out of sync.
+func resetBucket(tx kv.Tx, name []byte) (kv.Bucket, error) {
+	if tx.Bucket(name) != nil {
+		if err := tx.DeleteBucket(name); err != nil {
+			return nil, err
+		}
+	}
+	return tx.CreateBucketIfNotExists(name)
+}
+
+func itob(v int64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, uint64(v))
+	return b
+}
+
+func btoi(b []byte) int64 {
+	return int64(binary.BigEndian.Uint64(b))
+}
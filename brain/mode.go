@@ -3,14 +3,14 @@ package brain
 import (
 	"fmt"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // GetMode fetches the mode for a chat.
 func (store Store) GetMode(id int64) (Mode, error) {
 	var mode Mode
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
 		if v := tx.Bucket(bucket.Modes).Get(itob(id)); v != nil {
 			mode = Mode(btoi(v))
 		} else {
@@ -26,7 +26,7 @@ func (store Store) GetMode(id int64) (Mode, error) {
 
 // SetMode updates the mode for a chat.
 func (store Store) SetMode(id int64, mode Mode) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Batch(func(tx kv.Tx) error {
 		return tx.Bucket(bucket.Modes).Put(itob(id), itob(int64(mode)))
 	})
 	if err != nil {
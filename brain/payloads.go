@@ -4,8 +4,8 @@ import (
 	"fmt"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // IsDuplicate checks whether a given payload has been sent twice in a row.
@@ -15,7 +15,8 @@ func (store Store) IsDuplicate(id int64, payload string) (bool, error) {
 	now := time.Now()
 	isDuplicate := false
 
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Batch(func(tx kv.Tx) error {
+		isDuplicate = false
 		b := tx.Bucket(bucket.PrevPayloads)
 
 		// Check if previous payload was the same and if it was in so recent that it is a duplicate
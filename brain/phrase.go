@@ -4,11 +4,11 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
-	"sort"
+	"strings"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // AddPhrase stores a new phrase.
@@ -22,13 +22,23 @@ func (store Store) AddPhrase(id int64, phrase, explanation string, createdAt tim
 }
 
 // Abstract adding to reuse it for import.
-func phraseAdder(prefix []byte, p Phrase, createdAt time.Time, studyTime time.Time) func(*bolt.Tx) error {
-	return func(tx *bolt.Tx) error {
+func phraseAdder(prefix []byte, p Phrase, createdAt time.Time, studyTime time.Time) func(kv.Tx) error {
+	return func(tx kv.Tx) error {
 		bp := tx.Bucket(bucket.Phrases)
-		bz := tx.Bucket(bucket.Zeroscores)
 
-		// Ensure score is zero
+		// Ensure scores are zero and phrase starts with a fresh memory
 		p.Score = 0
+		p.Memory = Memory{}
+		p.ReverseScore = 0
+		p.ReverseMemory = Memory{}
+		p.ClozeScores = nil
+		p.ClozeMemories = nil
+		p.Leech = false
+		if p.Direction < DirectionForward || p.Direction > DirectionBoth {
+			p.Direction = DirectionForward
+		}
+		p.Alternatives = normAlternatives(p.Phrase, p.Alternatives)
+		p.Tags = normTags(p.Tags)
 
 		// Get phrase id
 		sequence, err := bp.NextSequence()
@@ -38,74 +48,38 @@ func phraseAdder(prefix []byte, p Phrase, createdAt time.Time, studyTime time.Ti
 		phraseID := itob(int64(sequence))
 		key := append(append([]byte{}, prefix...), phraseID...)
 
-		// Phrase to GOB
-		var buf bytes.Buffer
-		if err := gob.NewEncoder(&buf).Encode(p); err != nil {
-			return err
-		}
-
 		// Save phrase
-		if err := bp.Put(key, buf.Bytes()); err != nil {
-			return err
-		}
-
-		// Queue as new phrase
-		bn := tx.Bucket(bucket.NewPhrases)
-		if err := bn.Put(prefix, append(bn.Get(prefix), phraseID...)); err != nil {
+		if err := putPhrase(tx, key, p); err != nil {
 			return err
 		}
-
-		// Try to schedule it
-		var zeroscore int64
-		if v := bz.Get(prefix); v != nil {
-			zeroscore = btoi(v)
-		}
-		scheduled, err := scheduleNewPhrases(tx, prefix, studyTime, int(zeroscore))
-		if err != nil {
+		if err := indexTags(tx, key, nil, p.Tags); err != nil {
 			return err
 		}
-
-		fmt.Printf("prev zeroscore: %d, new phrases: %d, newly scheduled: %d\n", zeroscore, len(bn.Get(prefix))/8, scheduled)
-
-		if err := bz.Put(prefix, itob(zeroscore+int64(scheduled))); err != nil {
+		if err := indexExplanation(tx, key, "", p.Explanation); err != nil {
 			return err
 		}
 
-		// Save time phrase has been added
-		return tx.Bucket(bucket.PhraseAddTimes).Put(key, itob(createdAt.Unix()))
-	}
-}
-
-// FindPhrase returns a phrase belonging to the passed user that matches the passed function.
-func (store Store) FindPhrase(id int64, fn func(Phrase) bool) (Phrase, error) {
-	var p Phrase
-	err := store.db.View(func(tx *bolt.Tx) error {
-		c := tx.Bucket(bucket.Phrases).Cursor()
-		prefix := itob(id)
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			var tmp Phrase
-			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&tmp); err != nil {
+		// Queue cards as new phrases and try to schedule them
+		for _, card := range p.cards(key) {
+			if err := queueCard(tx, card, studyTime); err != nil {
 				return err
 			}
-			if fn(tmp) {
-				p = tmp
-				return nil
-			}
 		}
-		return nil
-	})
 
-	if err != nil {
-		return p, fmt.Errorf("failed to find phrase with id %d: %v", id, err)
+		// Save time phrase has been added
+		return setAddTime(tx, key, createdAt.Unix())
 	}
-	return p, nil
 }
 
-// DeletePhrase removes a phrase.
+// DeletePhrase moves a phrase to the trash.
+// It can be restored with RestorePhrase until the trash is purged.
 // Returns ErrNotFound if phrase doesn't exist.
 func (store Store) DeletePhrase(id int64, seq int) error {
 	key := append(itob(id), itob(int64(seq))...)
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
+		if err := trashPhrase(tx, key, time.Now()); err != nil {
+			return err
+		}
 		return phraseDeleter(tx, key)
 	})
 	if err != nil && err != ErrNotFound {
@@ -116,37 +90,47 @@ func (store Store) DeletePhrase(id int64, seq int) error {
 
 // Reuse deleting functionality to only have one place
 // to think about that all related buckets have been cleared.
-func phraseDeleter(tx *bolt.Tx, key []byte) error {
-	// Delete study time
-	if err := tx.Bucket(bucket.Studytimes).Delete(key); err != nil {
+// Studies stay in the phrase studies index until the phrase is purged from the trash.
+func phraseDeleter(tx kv.Tx, key []byte) error {
+	p, err := getPhrase(tx, key)
+	if err != nil {
 		return err
 	}
+	score := p.Score
+
+	// Delete cards and update scoretotal and zeroscore
+	for _, card := range p.cards(key) {
+		if err := removeCard(tx, card, &p); err != nil {
+			return err
+		}
+	}
 
 	// Delete add time
-	if err := tx.Bucket(bucket.PhraseAddTimes).Delete(key); err != nil {
+	if err := deleteAddTime(tx, key); err != nil {
 		return err
 	}
 
-	// Update scoretotal and zeroscore
-	p, err := getPhrase(tx, key)
-	if err != nil {
+	// Delete suspension
+	if err := tx.Bucket(bucket.Suspended).Delete(key); err != nil {
 		return err
 	}
-	if p.Score == 0 {
-		if err := updateZeroscore(tx, key[:8], -1); err != nil {
-			return err
-		}
-	} else {
-		if err := addCountToBucket(tx.Bucket(bucket.Scoretotals), key[:8], -p.Score); err != nil {
-			return err
-		}
+
+	// Delete tags and explanation from index
+	if err := indexTags(tx, key, p.Tags, nil); err != nil {
+		return err
+	}
+	if err := indexExplanation(tx, key, p.Explanation, ""); err != nil {
+		return err
 	}
 
 	// Delete phrase
+	if err := tx.Bucket(bucket.ScorePhrases).Delete(sortKey(key, int64(score))); err != nil {
+		return err
+	}
 	return tx.Bucket(bucket.Phrases).Delete(key)
 }
 
-func getPhrase(tx *bolt.Tx, key []byte) (Phrase, error) {
+func getPhrase(tx kv.Tx, key []byte) (Phrase, error) {
 	v := tx.Bucket(bucket.Phrases).Get(key)
 	var p Phrase
 	if v == nil {
@@ -155,10 +139,22 @@ func getPhrase(tx *bolt.Tx, key []byte) (Phrase, error) {
 	return p, gob.NewDecoder(bytes.NewReader(v)).Decode(&p)
 }
 
+// Save a phrase and keep the score index in sync.
+func putPhrase(tx kv.Tx, key []byte, p Phrase) error {
+	if err := indexScore(tx, key, p.Score); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		return err
+	}
+	return tx.Bucket(bucket.Phrases).Put(key, buf.Bytes())
+}
+
 // Adds a scoreUpdate to the zeroscore of a user.
 // zeroscore cannot be less than zero.
 // With each update we also check if we can schedule new phrases.
-func updateZeroscore(tx *bolt.Tx, prefix []byte, scoreUpdate int) error {
+func updateZeroscore(tx kv.Tx, prefix []byte, scoreUpdate int) error {
 	zeroscore := int64(scoreUpdate)
 	bz := tx.Bucket(bucket.Zeroscores)
 	if v := bz.Get(prefix); v != nil {
@@ -181,119 +177,141 @@ func updateZeroscore(tx *bolt.Tx, prefix []byte, scoreUpdate int) error {
 // Schedule new phrases for studying.
 // Pass the number of new phrases already scheduled.
 // Returns the number of phrases that have been additionally scheduled.
-func scheduleNewPhrases(tx *bolt.Tx, prefix []byte, studyTime time.Time, scheduled int) (int, error) {
-	toSchedule := maxNewStudies - scheduled
+// The limit of new phrases is taken from the user's settings.
+func scheduleNewPhrases(tx kv.Tx, prefix []byte, studyTime time.Time, scheduled int) (int, error) {
+	settings, err := getSettings(tx, prefix)
+	if err != nil {
+		return 0, err
+	}
+	toSchedule := settings.NewPhrases - scheduled
 	if toSchedule < 1 {
 		return 0, nil
 	}
 
 	bn := tx.Bucket(bucket.NewPhrases)
-	bs := tx.Bucket(bucket.Studytimes)
 	v := bn.Get(prefix)
-	next := itob(studyTime.Unix())
-	var i, o int
+	next := studyTime.Unix()
+	var i int
+	rest := []byte{}
 
 	// Schedule as many as allowed to schedule and as available from new phrases
-	for i < toSchedule {
-		o = i * 8
-		if o >= len(v) {
-			break
+	for o := 0; o+8 <= len(v); o += 8 {
+		phraseID := append(append([]byte{}, prefix...), v[o:o+8]...)
+		// Suspended phrases stay new until they are unsuspended
+		if i >= toSchedule || isSuspended(tx, phraseID) {
+			rest = append(rest, v[o:o+8]...)
+			continue
 		}
 		i++
 		// Save study time
-		phraseID := append(append([]byte{}, prefix...), v[o:o+8]...)
-		if err := bs.Put(phraseID, next); err != nil {
+		if err := setStudytime(tx, phraseID, next); err != nil {
 			return 0, err
 		}
 	}
 
 	// Remove scheduled phrases from new phrases bucket
-	return i, bn.Put(prefix, v[o:])
+	return i, bn.Put(prefix, rest)
 }
 
 // IDPhrase is a phrase format that also contains ID.
 type IDPhrase struct {
-	ID          int64  `json:"id"`
-	Phrase      string `json:"phrase"`
-	Explanation string `json:"explanation"`
-	Score       int    `json:"score"`
-	Added       int64  `json:"added"`
-}
-
-type idPhrases []IDPhrase
-
-func (p idPhrases) Len() int {
-	return len(p)
-}
-
-func (p idPhrases) Less(i, j int) bool {
-	return p[i].Added > p[j].Added
-}
-
-func (p idPhrases) Swap(i, j int) {
-	p[j], p[i] = p[i], p[j]
+	ID           int64     `json:"id"`
+	Phrase       string    `json:"phrase"`
+	Explanation  string    `json:"explanation"`
+	Score        int       `json:"score"`
+	Added        int64     `json:"added"`
+	Direction    Direction `json:"direction"`
+	ReverseScore int       `json:"reverseScore"`
+	Alternatives []string  `json:"alternatives"`
+	Tags         []string  `json:"tags"`
+	Suspended    bool      `json:"suspended"`
+	Leech        bool      `json:"leech"`
 }
 
 // GetAllPhrases returns all phrases for a given user sorted by the time they have been added.
-// Need to load all of the user's phrases in memory to be able to sort them.
+// Use QueryPhrases to get them in pages.
 func (store Store) GetAllPhrases(id int64) ([]IDPhrase, error) {
-	var phrases idPhrases
+	phrases, _, err := store.QueryPhrases(id, PhraseQuery{})
+	if err != nil {
+		return phrases, fmt.Errorf("failed to get all phrases for %d: %v", id, err)
+	}
+	return phrases, nil
+}
 
-	err := store.db.View(func(tx *bolt.Tx) error {
-		c := tx.Bucket(bucket.Phrases).Cursor()
-		bt := tx.Bucket(bucket.PhraseAddTimes)
-		prefix := itob(id)
+// Add ID, add time and suspension to a decoded phrase.
+func newIDPhrase(tx kv.Tx, k []byte, p Phrase) IDPhrase {
+	var t int64
+	if tb := tx.Bucket(bucket.PhraseAddTimes).Get(k); tb != nil {
+		t = btoi(tb)
+	}
 
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			var p Phrase
-			if err := gob.NewDecoder(bytes.NewBuffer(v)).Decode(&p); err != nil {
-				return err
-			}
+	suspended := tx.Bucket(bucket.Suspended).Get(k) != nil
 
-			seq := btoi(k[8:])
-			var t int64
-			if tb := bt.Get(k); tb != nil {
-				t = btoi(tb)
-			}
+	return IDPhrase{btoi(k[8:]), p.Phrase, p.Explanation, p.Score, t, p.Direction, p.ReverseScore, p.Alternatives, p.Tags, suspended, p.Leech}
+}
 
-			phrases = append(phrases, IDPhrase{seq, p.Phrase, p.Explanation, p.Score, t})
+// SetAlternatives replaces the alternative answers of a phrase.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) SetAlternatives(id int64, seq int, alternatives []string) error {
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		p, err := getPhrase(tx, key)
+		if err != nil {
+			return err
 		}
-
-		return nil
+		p.Alternatives = normAlternatives(p.Phrase, alternatives)
+		return putPhrase(tx, key, p)
 	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to set alternatives for key %x to %v: %v", key, alternatives, err)
+	}
+	return err
+}
 
-	sort.Sort(phrases)
-
-	if err != nil {
-		return phrases, fmt.Errorf("failed to get all phrases for %d: %v", id, err)
+// Trim alternatives, remove empty ones, duplicates and the phrase itself.
+func normAlternatives(phrase string, alternatives []string) []string {
+	var norm []string
+	seen := map[string]bool{phrase: true}
+	for _, a := range alternatives {
+		a = strings.TrimSpace(a)
+		if a == "" || seen[a] {
+			continue
+		}
+		seen[a] = true
+		norm = append(norm, a)
 	}
-	return phrases, nil
+	return norm
 }
 
 // UpdatePhrase updates an existing phrase.
+// An updated phrase is no leech anymore.
+// If the clozes of the phrase change, their cards are added or removed.
 // Return ErrNotFound if phrase does not exist.
 func (store Store) UpdatePhrase(id int64, seq int, phrase, explanation string) error {
 	key := append(itob(id), itob(int64(seq))...)
-	err := store.db.Update(func(tx *bolt.Tx) error {
-		bp := tx.Bucket(bucket.Phrases)
+	err := store.db.Update(func(tx kv.Tx) error {
 		// Get existing phrase
 		p, err := getPhrase(tx, key)
 		if err != nil {
 			return err
 		}
 		// Update
+		prev := p
 		p.Phrase = phrase
 		p.Explanation = explanation
-		// Save phrase
-		var buf bytes.Buffer
-		if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		p.Leech = false
+		if err := updateCards(tx, key, prev, &p); err != nil {
+			return err
+		}
+		if err := indexExplanation(tx, key, prev.Explanation, p.Explanation); err != nil {
 			return err
 		}
-		return bp.Put(key, buf.Bytes())
+		// Save phrase
+		return putPhrase(tx, key, p)
 	})
 
-	if err != nil {
-		return fmt.Errorf("failed to update phrase for key %x: %s - %s: %v", key, phrase, explanation, err)
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to update phrase for key %x: %s - %s: %v", key, phrase, explanation, err)
 	}
-	return nil
+	return err
 }
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // Wrap data as Profile.
@@ -30,7 +30,7 @@ func (p profile) Timezone() float64 { return p.data.Timezone }
 // Returns ErrNotFound if none found or cache is older than profileMaxCacheTime.
 func (store Store) GetProfile(id int64) (Profile, error) {
 	var p profileData
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
 		v := tx.Bucket(bucket.Profiles).Get(itob(id))
 		if v == nil {
 			return ErrNotFound
@@ -59,7 +59,7 @@ func (store Store) SetProfile(id int64, p Profile, cachedAt time.Time) error {
 		Timezone:  p.Timezone(),
 		CacheTime: cachedAt,
 	}
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		var buf bytes.Buffer
 		if err := gob.NewEncoder(&buf).Encode(data); err != nil {
 			return err
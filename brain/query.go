@@ -0,0 +1,443 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// PhraseSort is the order phrases are returned in by a query.
+type PhraseSort int
+
+const (
+	// SortAdded sorts phrases by the time they have been added, newest first.
+	SortAdded PhraseSort = iota
+	// SortScore sorts phrases by score, lowest first.
+	SortScore
+	// SortDue sorts phrases by the time their next card is due, earliest first.
+	// Phrases that haven't been scheduled yet come last.
+	SortDue
+)
+
+var sortNames = []string{"added", "score", "due"}
+
+// DueFilter limits a query to phrases in a due state.
+type DueFilter int
+
+const (
+	// DueAny matches all phrases.
+	DueAny DueFilter = iota
+	// DueNow matches phrases with a card ready to study. Suspended phrases are never ready.
+	DueNow
+	// DueLater matches scheduled phrases that are not ready to study.
+	DueLater
+	// DueNew matches phrases that haven't been scheduled yet.
+	DueNew
+)
+
+var dueNames = []string{"any", "now", "later", "new"}
+
+// PhraseQuery describes which phrases of a user to get and in which order.
+// The zero value gets all phrases sorted by SortAdded.
+type PhraseQuery struct {
+	Sort PhraseSort
+	// MinScore and MaxScore limit the score of phrases if they are set.
+	MinScore *int
+	MaxScore *int
+	Due      DueFilter
+	// Search matches phrases with a phrase, explanation, alternative or tag containing it.
+	// Case is ignored.
+	Search string
+	// Tag matches phrases with the tag.
+	Tag string
+	// Limit is the maximum number of phrases to get. 0 gets all phrases.
+	Limit int
+	// Cursor continues a previous query after the last phrase it returned.
+	Cursor string
+}
+
+// ParsePhraseQuery reads a query from URL parameters.
+// Parameters are sort, minScore, maxScore, due, q, tag, limit and cursor.
+func ParsePhraseQuery(v url.Values) (PhraseQuery, error) {
+	q := PhraseQuery{
+		Search: v.Get("q"),
+		Tag:    v.Get("tag"),
+		Cursor: v.Get("cursor"),
+	}
+	if s := v.Get("sort"); s != "" {
+		i := indexOf(sortNames, s)
+		if i < 0 {
+			return q, fmt.Errorf("invalid sort '%s'", s)
+		}
+		q.Sort = PhraseSort(i)
+	}
+	if s := v.Get("due"); s != "" {
+		i := indexOf(dueNames, s)
+		if i < 0 {
+			return q, fmt.Errorf("invalid due '%s'", s)
+		}
+		q.Due = DueFilter(i)
+	}
+	var err error
+	if q.MinScore, err = parseScore(v, "minScore"); err != nil {
+		return q, err
+	}
+	if q.MaxScore, err = parseScore(v, "maxScore"); err != nil {
+		return q, err
+	}
+	if s := v.Get("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("invalid limit '%s'", s)
+		}
+		q.Limit = n
+	}
+	return q, nil
+}
+
+// Get an optional score from URL parameters.
+func parseScore(v url.Values, name string) (*int, error) {
+	s := v.Get(name)
+	if s == "" {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s '%s'", name, s)
+	}
+	return &n, nil
+}
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// QueryPhrases returns the phrases of a user matching q
+// and a cursor to get the next page, which is empty on the last page.
+// Returns ErrInvalidCursor if the cursor of q is malformed.
+func (store Store) QueryPhrases(id int64, q PhraseQuery) ([]IDPhrase, string, error) {
+	var phrases []IDPhrase
+	next, err := store.eachPhrase(id, q, func(p IDPhrase) error {
+		phrases = append(phrases, p)
+		return nil
+	})
+	return phrases, next, err
+}
+
+// EachPhrase calls fn for each phrase of a user matching q in order.
+// At most q.Limit phrases are passed if it is set.
+// Phrases are read a page at a time and fn is called outside of the transaction,
+// so it can take its time, for example to write to a slow client.
+// Iteration stops at the first error returned by fn.
+// Returns ErrInvalidCursor if the cursor of q is malformed.
+func (store Store) EachPhrase(id int64, q PhraseQuery, fn func(IDPhrase) error) error {
+	limit := q.Limit
+	count := 0
+	for {
+		q.Limit = eachPageSize
+		if limit > 0 && limit-count < eachPageSize {
+			q.Limit = limit - count
+		}
+		phrases, next, err := store.QueryPhrases(id, q)
+		if err != nil {
+			return err
+		}
+		for _, p := range phrases {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		count += len(phrases)
+		if next == "" || limit > 0 && count >= limit {
+			return nil
+		}
+		q.Cursor = next
+	}
+}
+
+// The position of a phrase in the index a query walks.
+// seq is the last part of the index key,
+// which is the phrase ID or for the due index the ID of the earliest card.
+type queryKey struct {
+	key   []byte
+	value int64
+	seq   []byte
+}
+
+// Call fn for each phrase of a user matching q in order
+// and return a cursor to get the next page, which is empty on the last page.
+// The index of the sort is walked from the cursor on, so a page only reads the phrases it needs.
+// fn runs inside a read transaction and must not write to the store.
+// Iteration stops at the first error returned by fn.
+// Returns ErrInvalidCursor if the cursor of q is malformed.
+func (store Store) eachPhrase(id int64, q PhraseQuery, fn func(IDPhrase) error) (string, error) {
+	var after *queryKey
+	if q.Cursor != "" {
+		c, err := parseCursor(q.Cursor)
+		if err != nil {
+			return "", err
+		}
+		after = &c
+	}
+	var next string
+	err := store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		now := time.Now().Unix()
+		bp := tx.Bucket(bucket.Phrases)
+		bt := tx.Bucket(bucket.Tags)
+		search := strings.ToLower(q.Search)
+		count := 0
+		var last queryKey
+
+		// Decode and filter phrases in the order of the index
+		visit := func(qk queryKey) (bool, error) {
+			if q.Tag != "" && bt.Get(tagKey(prefix, q.Tag, qk.key[8:])) == nil {
+				return true, nil
+			}
+			v := bp.Get(qk.key)
+			if v == nil {
+				return true, nil
+			}
+			var p Phrase
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+				return false, err
+			}
+			ip := newIDPhrase(tx, qk.key, p)
+			if q.Due != DueAny {
+				due, scheduled := phraseDue(tx, qk.key, p)
+				if !q.Due.matches(due, scheduled, ip.Suspended, now) {
+					return true, nil
+				}
+			}
+			if !ip.matches(q, search) {
+				return true, nil
+			}
+			// Only continue with another page if there is one more phrase
+			if q.Limit > 0 && count == q.Limit {
+				next = formatCursor(last)
+				return false, nil
+			}
+			if err := fn(ip); err != nil {
+				return false, err
+			}
+			last = qk
+			count++
+			return true, nil
+		}
+
+		switch q.Sort {
+		case SortScore:
+			var from int64
+			if q.MinScore != nil && *q.MinScore > 0 {
+				from = int64(*q.MinScore)
+			}
+			return walkIndex(tx, bucket.ScorePhrases, prefix, after, from, false, func(value int64, seq []byte) (bool, error) {
+				if q.MaxScore != nil && value > int64(*q.MaxScore) {
+					return false, nil
+				}
+				return visit(queryKey{append(append([]byte{}, prefix...), seq...), value, seq})
+			})
+		case SortDue:
+			return walkDue(tx, prefix, q.Due, after, now, visit)
+		default:
+			return walkIndex(tx, bucket.AddedPhrases, prefix, after, 0, true, func(value int64, seq []byte) (bool, error) {
+				return visit(queryKey{append(append([]byte{}, prefix...), seq...), value, seq})
+			})
+		}
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to query phrases for %d: %v", id, err)
+	}
+	return next, nil
+}
+
+// Walk an index with keys id+value+seq of a user in order and call fn until it returns false.
+// Walking starts after the position of the cursor.
+// Walking forward starts at from if the cursor is before it; walking in reverse starts at the end.
+// fn gets a copy of seq, since it might be kept after the transaction.
+func walkIndex(tx kv.Tx, b, prefix []byte, after *queryKey, from int64, reverse bool, fn func(value int64, seq []byte) (bool, error)) error {
+	c := tx.Bucket(b).Cursor()
+	var k []byte
+	if reverse {
+		pos := append(append([]byte{}, prefix...), bytes.Repeat([]byte{0xff}, 16)...)
+		if after != nil {
+			pos = append(append(append([]byte{}, prefix...), itob(after.value)...), after.seq...)
+		}
+		// Seek finds the first key at or after pos, so the walk starts with the key before
+		if k, _ = c.Seek(pos); k == nil {
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+	} else if after != nil && after.value >= from {
+		pos := append(append(append([]byte{}, prefix...), itob(after.value)...), after.seq...)
+		if k, _ = c.Seek(pos); bytes.Equal(k, pos) {
+			k, _ = c.Next()
+		}
+	} else {
+		k, _ = c.Seek(append(append([]byte{}, prefix...), itob(from)...))
+	}
+	for ; k != nil && bytes.HasPrefix(k, prefix); k = step(c, reverse) {
+		ok, err := fn(btoi(k[8:16]), append([]byte{}, k[16:]...))
+		if err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+func step(c kv.Cursor, reverse bool) []byte {
+	if reverse {
+		k, _ := c.Prev()
+		return k
+	}
+	k, _ := c.Next()
+	return k
+}
+
+// Walk the phrases of a user ordered by the time their next card is due.
+// Scheduled phrases come from the due index, where a phrase is placed by its earliest card.
+// Phrases that haven't been scheduled yet follow in ID order with the highest possible due time.
+func walkDue(tx kv.Tx, prefix []byte, filter DueFilter, after *queryKey, now int64, visit func(queryKey) (bool, error)) error {
+	done := false
+	if filter != DueNew && (after == nil || after.value != math.MaxInt64) {
+		bs := tx.Bucket(bucket.Studytimes)
+		err := walkIndex(tx, bucket.Dues, prefix, after, 0, false, func(t int64, seq []byte) (bool, error) {
+			// Phrases after this one are not ready either
+			if filter == DueNow && t > now {
+				done = true
+				return false, nil
+			}
+			card := append(append([]byte{}, prefix...), seq...)
+			pk, _ := phraseKey(card)
+			p, err := getPhrase(tx, pk)
+			if err == ErrNotFound {
+				return true, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			// Skip all but the earliest card of a phrase
+			for _, other := range p.cards(pk) {
+				if v := bs.Get(other); v != nil && (btoi(v) < t || btoi(v) == t && bytes.Compare(other[8:], seq) < 0) {
+					return true, nil
+				}
+			}
+			ok, err := visit(queryKey{pk, t, seq})
+			done = !ok
+			return ok, err
+		})
+		if err != nil || done {
+			return err
+		}
+		after = nil
+	}
+	if filter == DueNow || filter == DueLater {
+		return nil
+	}
+
+	c := tx.Bucket(bucket.Phrases).Cursor()
+	var k, v []byte
+	if after != nil {
+		pos := append(append([]byte{}, prefix...), after.seq...)
+		if k, v = c.Seek(pos); bytes.Equal(k, pos) {
+			k, v = c.Next()
+		}
+	} else {
+		k, v = c.Seek(prefix)
+	}
+	for ; k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var p Phrase
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+			return err
+		}
+		if _, scheduled := phraseDue(tx, k, p); scheduled {
+			continue
+		}
+		key := append([]byte{}, k...)
+		if ok, err := visit(queryKey{key, math.MaxInt64, key[8:]}); err != nil || !ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get the time the next card of a phrase is due.
+// Returns false if no card of the phrase has been scheduled yet.
+func phraseDue(tx kv.Tx, key []byte, p Phrase) (int64, bool) {
+	bs := tx.Bucket(bucket.Studytimes)
+	var due int64
+	scheduled := false
+	for _, card := range p.cards(key) {
+		if v := bs.Get(card); v != nil && (!scheduled || btoi(v) < due) {
+			due = btoi(v)
+			scheduled = true
+		}
+	}
+	return due, scheduled
+}
+
+// Check if a phrase with the given next due time is in the due state of the filter.
+// Suspended phrases are never ready.
+func (f DueFilter) matches(due int64, scheduled, suspended bool, now int64) bool {
+	switch f {
+	case DueNow:
+		return scheduled && due <= now && !suspended
+	case DueLater:
+		return scheduled && (due > now || suspended)
+	case DueNew:
+		return !scheduled
+	}
+	return true
+}
+
+// Check if a phrase matches the filters of a query that need the decoded phrase.
+// search is expected to be in lower case.
+func (p IDPhrase) matches(q PhraseQuery, search string) bool {
+	if q.MinScore != nil && p.Score < *q.MinScore || q.MaxScore != nil && p.Score > *q.MaxScore {
+		return false
+	}
+	if search == "" {
+		return true
+	}
+	for _, s := range append(append([]string{p.Phrase, p.Explanation}, p.Alternatives...), p.Tags...) {
+		if strings.Contains(strings.ToLower(s), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// A cursor is the sort value and the last part of the index key of the last phrase of a page.
+func formatCursor(k queryKey) string {
+	return strconv.FormatInt(k.value, 10) + "." + strconv.FormatInt(btoi(k.seq), 10)
+}
+
+func parseCursor(cursor string) (queryKey, error) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return queryKey{}, ErrInvalidCursor
+	}
+	value, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return queryKey{}, ErrInvalidCursor
+	}
+	seq, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return queryKey{}, ErrInvalidCursor
+	}
+	return queryKey{value: value, seq: itob(seq)}, nil
+}
@@ -0,0 +1,156 @@
+package brain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Users are ranked by score in cohorts.
+// The cohort rankAll contains all users,
+// the other cohorts contain the users registered in the same month.
+//
+// Each cohort is a Fenwick tree over all possible scores.
+// A node of the tree counts the users in a range of scores.
+// Only nodes that count any users are stored.
+// This way updating the score of a user and getting the rank take O(log rankMaxScore).
+const rankAll = 0
+
+// GetRank returns the score and ranks of a user.
+// Unlike UserStats, it can be called at any time and only the score fields of Stats are set.
+func (store Store) GetRank(id int64) (Stats, error) {
+	var s Stats
+	err := store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		s.Score = getScoretotal(tx, prefix)
+		s.Rank, s.Percentile, s.MonthRank = getRank(tx, prefix, s.Score)
+		return nil
+	})
+	if err != nil {
+		return s, fmt.Errorf("failed to get rank for %d: %v", id, err)
+	}
+	return s, nil
+}
+
+// Get the rank of a user among all users and among the users registered in the same month.
+// Also returns the percentage of users with a lower or the same score.
+// Rank of the month is 0 if the user has no register date.
+func getRank(tx kv.Tx, prefix []byte, score int) (int, int, int) {
+	b := tx.Bucket(bucket.Ranks)
+	rank, percentile, monthRank := 1, 100, 0
+	for _, cohort := range rankCohorts(tx, prefix) {
+		total := countRanks(b, cohort, rankMaxScore)
+		higher := total - countRanks(b, cohort, score)
+		if cohort != rankAll {
+			monthRank = int(higher) + 1
+			continue
+		}
+		rank = int(higher) + 1
+		if total > 0 {
+			percentile = int((total - higher) * 100 / total)
+		}
+	}
+	return rank, percentile, monthRank
+}
+
+// Set the score total of a user and update the ranks.
+// Score totals cannot be less than zero.
+func updateScoretotal(tx kv.Tx, prefix []byte, update int) error {
+	b := tx.Bucket(bucket.Scoretotals)
+	v := b.Get(prefix)
+	prev := 0
+	if v != nil {
+		prev = int(btoi(v))
+	}
+	score := prev + update
+	if score < 0 {
+		score = 0
+	}
+	if err := b.Put(prefix, itob(int64(score))); err != nil {
+		return err
+	}
+	// Users without score total are not ranked yet
+	if v == nil {
+		prev = -1
+	} else if score == prev {
+		return nil
+	}
+	return moveRank(tx, prefix, rankCohorts(tx, prefix), prev, score)
+}
+
+// Move a user from prev to next score in the given cohorts.
+// Pass -1 as prev or next score to only add or only remove the user.
+func moveRank(tx kv.Tx, prefix []byte, cohorts []int64, prev, next int) error {
+	b := tx.Bucket(bucket.Ranks)
+	for _, cohort := range cohorts {
+		if prev >= 0 {
+			if err := addRank(b, cohort, prev, -1); err != nil {
+				return err
+			}
+		}
+		if next >= 0 {
+			if err := addRank(b, cohort, next, 1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get the cohorts a user is ranked in.
+func rankCohorts(tx kv.Tx, prefix []byte) []int64 {
+	cohorts := []int64{rankAll}
+	if v := tx.Bucket(bucket.RegisterDates).Get(prefix); v != nil {
+		cohorts = append(cohorts, rankMonth(time.Unix(btoi(v), 0)))
+	}
+	return cohorts
+}
+
+// Get the cohort of users registered in the same month as t.
+func rankMonth(t time.Time) int64 {
+	y, m, _ := t.UTC().Date()
+	return int64(y)*12 + int64(m)
+}
+
+// Add count to the number of users with the given score in a cohort.
+func addRank(b kv.Bucket, cohort int64, score int, count int64) error {
+	for i := rankIndex(score); i <= rankMaxScore+1; i += i & -i {
+		k := append(itob(cohort), itob(i)...)
+		n := count
+		if v := b.Get(k); v != nil {
+			n += btoi(v)
+		}
+		var err error
+		if n <= 0 {
+			err = b.Delete(k)
+		} else {
+			err = b.Put(k, itob(n))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count the users of a cohort with a score less than or equal to the given score.
+func countRanks(b kv.Bucket, cohort int64, score int) int64 {
+	var n int64
+	for i := rankIndex(score); i > 0; i -= i & -i {
+		if v := b.Get(append(itob(cohort), itob(i)...)); v != nil {
+			n += btoi(v)
+		}
+	}
+	return n
+}
+
+// Get the index of a score in the tree.
+// Indexes start at 1, higher scores share the last index.
+func rankIndex(score int) int64 {
+	if score > rankMaxScore {
+		score = rankMaxScore
+	}
+	return int64(score) + 1
+}
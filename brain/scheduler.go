@@ -0,0 +1,272 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Names of the available schedulers.
+// They are stored per user in the DB and should never change.
+const (
+	// SchedulerTable uses fixed intervals indexed by the score of a phrase.
+	SchedulerTable = "table"
+	// SchedulerSM2 implements the SuperMemo 2 algorithm with an ease factor per phrase.
+	SchedulerSM2 = "sm2"
+	// SchedulerFSRS implements a simplified version of the Free Spaced Repetition Scheduler
+	// tracking stability and difficulty per phrase.
+	SchedulerFSRS = "fsrs"
+)
+
+// Scheduler decides when a phrase should be studied next.
+// Schedulers must not touch the score of a phrase,
+// since it is used for zeroscores and scoretotals independent of the scheduler.
+type Scheduler interface {
+	// Next updates the memory of a phrase after it has been studied
+	// and returns the interval until the phrase should be studied again.
+	// The score of p is already updated.
+	// elapsed is the time since the phrase has been studied the last time.
+	Next(p *Phrase, g Grade, elapsed time.Duration) time.Duration
+	// Convert initializes the memory of a phrase that has been scheduled by another scheduler.
+	// interval is the interval the phrase is currently scheduled with.
+	Convert(p *Phrase, interval time.Duration)
+}
+
+// Memory is the per phrase state of a Scheduler.
+// It is stored as part of the phrase.
+type Memory struct {
+	// Interval is the interval the scheduler has chosen at the last study.
+	// The study time of the phrase is further apart,
+	// since the interval multiplier of the user and diffusion are applied to it.
+	Interval time.Duration
+	// Last is the time of the last study.
+	// It is zero if the phrase hasn't been studied since the field has been added.
+	Last time.Time
+	// Ease is the SM-2 ease factor.
+	Ease float64
+	// Stability is the FSRS stability in days.
+	// It is the interval after which the phrase is remembered with a probability of 90%.
+	Stability float64
+	// Difficulty is the FSRS difficulty between 1 and 10.
+	Difficulty float64
+}
+
+var schedulers = map[string]Scheduler{
+	SchedulerTable: tableScheduler{},
+	SchedulerSM2:   sm2Scheduler{},
+	SchedulerFSRS:  fsrsScheduler{},
+}
+
+// GetScheduler returns the name of the scheduler a user studies with.
+func (store Store) GetScheduler(id int64) (string, error) {
+	var name string
+	err := store.db.View(func(tx kv.Tx) error {
+		name, _ = getScheduler(tx, itob(id))
+		return nil
+	})
+	if err != nil {
+		return name, fmt.Errorf("failed to get scheduler for id %d: %v", id, err)
+	}
+	return name, nil
+}
+
+// SetScheduler changes the scheduler a user studies with.
+// The memory of all phrases is converted for the new scheduler.
+// Study times are kept as they are, so no progress is lost.
+// Returns ErrNotFound if there is no scheduler with the given name.
+// Users can also change their scheduler with SetSettings.
+func (store Store) SetScheduler(id int64, name string) error {
+	if _, ok := schedulers[name]; !ok {
+		return ErrNotFound
+	}
+	err := store.db.Update(func(tx kv.Tx) error {
+		return setScheduler(tx, itob(id), name)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set scheduler for id %d to %s: %v", id, name, err)
+	}
+	return nil
+}
+
+// Change the scheduler of a user and convert the memory of all scheduled cards.
+// Nothing changes if the user already uses the scheduler.
+func setScheduler(tx kv.Tx, prefix []byte, name string) error {
+	s, ok := schedulers[name]
+	if !ok {
+		return fmt.Errorf("unknown scheduler '%s'", name)
+	}
+	if prev, _ := getScheduler(tx, prefix); prev == name {
+		return nil
+	}
+
+	bp := tx.Bucket(bucket.Phrases)
+	bs := tx.Bucket(bucket.Studytimes)
+	// Collect changes first, since updating a bucket while iterating it is unsafe
+	updates := map[string]Phrase{}
+	c := bp.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var p Phrase
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+			return err
+		}
+		changed := false
+		for _, card := range p.cards(k) {
+			// Only cards that have been scheduled have a memory
+			if bs.Get(card) == nil {
+				continue
+			}
+			score, memory := p.card(card)
+			interval := memory.Interval
+			if interval == 0 {
+				interval = tableInterval(*score)
+			}
+			c := Phrase{Phrase: p.Phrase, Explanation: p.Explanation, Score: *score}
+			s.Convert(&c, interval)
+			c.Memory.Interval = interval
+			c.Memory.Last = memory.Last
+			*memory = c.Memory
+			changed = true
+		}
+		if changed {
+			updates[string(k)] = p
+		}
+	}
+	for k, p := range updates {
+		if err := putPhrase(tx, []byte(k), p); err != nil {
+			return err
+		}
+	}
+
+	return tx.Bucket(bucket.Schedulers).Put(prefix, []byte(name))
+}
+
+// Returns the scheduler for a user and its name.
+// Falls back to the table scheduler.
+func getScheduler(tx kv.Tx, prefix []byte) (string, Scheduler) {
+	if v := tx.Bucket(bucket.Schedulers).Get(prefix); v != nil {
+		if s, ok := schedulers[string(v)]; ok {
+			return string(v), s
+		}
+	}
+	return SchedulerTable, schedulers[SchedulerTable]
+}
+
+// The interval for a score according to studyIntervals.
+func tableInterval(score int) time.Duration {
+	if score >= len(studyIntervals) {
+		score = len(studyIntervals) - 1
+	}
+	return studyIntervals[score]
+}
+
+// tableScheduler uses the score of a phrase as index for studyIntervals.
+type tableScheduler struct{}
+
+func (tableScheduler) Next(p *Phrase, _ Grade, _ time.Duration) time.Duration {
+	return tableInterval(p.Score)
+}
+
+func (tableScheduler) Convert(*Phrase, time.Duration) {}
+
+// sm2Scheduler multiplies the previous interval by an ease factor.
+// The ease factor is adjusted with each answer.
+// See https://www.supermemo.com/en/archives1990-2015/english/ol/sm2
+type sm2Scheduler struct{}
+
+func (sm2Scheduler) Next(p *Phrase, g Grade, _ time.Duration) time.Duration {
+	m := &p.Memory
+	if m.Ease == 0 {
+		m.Ease = sm2InitialEase
+	}
+
+	q := sm2Qualities[g]
+	m.Ease += 0.1 - float64(5-q)*(0.08+float64(5-q)*0.02)
+	if m.Ease < sm2MinEase {
+		m.Ease = sm2MinEase
+	}
+
+	// Start over after failing
+	if q < 3 {
+		return studyIntervals[0]
+	}
+	if m.Interval < 24*time.Hour {
+		return 24 * time.Hour
+	}
+	if m.Interval < 6*24*time.Hour {
+		return 6 * 24 * time.Hour
+	}
+	return time.Duration(float64(m.Interval) * m.Ease)
+}
+
+func (sm2Scheduler) Convert(p *Phrase, _ time.Duration) {
+	p.Memory.Ease = sm2InitialEase
+}
+
+// Map grades to SM-2 response qualities from 0 to 5.
+var sm2Qualities = map[Grade]int{
+	GradeAgain: 1,
+	GradeHard:  3,
+	GradeGood:  4,
+	GradeEasy:  5,
+}
+
+// fsrsScheduler estimates stability and difficulty of a phrase
+// and schedules the phrase for when it is remembered with a probability of 90%.
+// See https://github.com/open-spaced-repetition/fsrs4anki/wiki/The-Algorithm
+type fsrsScheduler struct{}
+
+func (fsrsScheduler) Next(p *Phrase, grade Grade, elapsed time.Duration) time.Duration {
+	m := &p.Memory
+	// FSRS grades go from 1 (again) to 4 (easy)
+	g := int(grade)
+
+	if m.Stability == 0 {
+		// First study
+		m.Stability = fsrsWeights[g-1]
+		m.Difficulty = fsrsInitialDifficulty(g)
+	} else {
+		days := elapsed.Hours() / 24
+		if days < 0 {
+			days = 0
+		}
+		r := math.Pow(1+days/(9*m.Stability), -1)
+		w := fsrsWeights
+
+		// Update difficulty and revert slightly to the mean
+		d := m.Difficulty - w[6]*float64(g-3)
+		d = w[7]*fsrsInitialDifficulty(3) + (1-w[7])*d
+		m.Difficulty = math.Min(math.Max(d, 1), 10)
+
+		if grade == GradeAgain {
+			m.Stability = w[11] * math.Pow(m.Difficulty, -w[12]) * (math.Pow(m.Stability+1, w[13]) - 1) * math.Exp(w[14]*(1-r))
+		} else {
+			bonus := 1.0
+			if grade == GradeHard {
+				bonus = w[15]
+			} else if grade == GradeEasy {
+				bonus = w[16]
+			}
+			m.Stability *= 1 + math.Exp(w[8])*(11-m.Difficulty)*math.Pow(m.Stability, -w[9])*(math.Exp(w[10]*(1-r))-1)*bonus
+		}
+	}
+
+	if grade == GradeAgain {
+		return studyIntervals[0]
+	}
+	return time.Duration(m.Stability * 24 * float64(time.Hour))
+}
+
+func (fsrsScheduler) Convert(p *Phrase, interval time.Duration) {
+	// With a retention of 90% the interval equals the stability
+	p.Memory.Stability = interval.Hours() / 24
+	p.Memory.Difficulty = fsrsInitialDifficulty(3)
+}
+
+func fsrsInitialDifficulty(g int) float64 {
+	return math.Min(math.Max(fsrsWeights[4]-float64(g-3)*fsrsWeights[5], 1), 10)
+}
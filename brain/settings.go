@@ -0,0 +1,148 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Settings are the study preferences of a user.
+type Settings struct {
+	// NewPhrases is the maximum number of new phrases studied at a time.
+	NewPhrases int `json:"newPhrases"`
+	// IntervalMultiplier is applied to all intervals a phrase is scheduled with.
+	IntervalMultiplier float64 `json:"intervalMultiplier"`
+	// QuietStart and QuietEnd are the hours of the day in between which no notifications are sent.
+	// There are no quiet hours if both are the same.
+	QuietStart int `json:"quietStart"`
+	QuietEnd   int `json:"quietEnd"`
+	// NotifyMinCount is the number of studies that need to be due before notifying the user.
+	NotifyMinCount int `json:"notifyMinCount"`
+	// MultipleChoice lets the user pick the answer from a few options instead of typing it.
+	MultipleChoice bool `json:"multipleChoice"`
+	// DailyStudies and DailyAdds are the goals for studying and adding phrases each day.
+	// There is no goal if set to 0.
+	DailyStudies int `json:"dailyStudies"`
+	DailyAdds    int `json:"dailyAdds"`
+	// Scheduler is the name of the scheduler phrases are studied with.
+	// It is stored in its own bucket, since changing it converts the memory of all phrases.
+	Scheduler string `json:"scheduler"`
+}
+
+// DefaultSettings are used for users that haven't changed their settings.
+var DefaultSettings = Settings{
+	NewPhrases:         maxNewStudies,
+	IntervalMultiplier: 1,
+	QuietStart:         nightStart,
+	QuietEnd:           nightEnd,
+	NotifyMinCount:     dueMinCount,
+	Scheduler:          SchedulerTable,
+}
+
+// Validate returns an error describing the first invalid setting.
+func (s Settings) Validate() error {
+	if s.NewPhrases < 0 {
+		return errors.New("new phrases cannot be negative")
+	}
+	if s.IntervalMultiplier < minIntervalMultiplier || s.IntervalMultiplier > maxIntervalMultiplier {
+		return fmt.Errorf("interval multiplier must be between %v and %v", minIntervalMultiplier, maxIntervalMultiplier)
+	}
+	if s.QuietStart < 0 || s.QuietStart > 23 || s.QuietEnd < 0 || s.QuietEnd > 23 {
+		return errors.New("quiet hours must be between 0 and 23")
+	}
+	if s.NotifyMinCount < 1 {
+		return errors.New("notify min count must be at least 1")
+	}
+	if s.DailyStudies < 0 || s.DailyAdds < 0 {
+		return errors.New("daily goals cannot be negative")
+	}
+	if _, ok := schedulers[s.Scheduler]; !ok {
+		return fmt.Errorf("unknown scheduler '%s'", s.Scheduler)
+	}
+	return nil
+}
+
+// GetSettings returns the settings of a user.
+// Returns DefaultSettings if the user hasn't changed them.
+func (store Store) GetSettings(id int64) (Settings, error) {
+	var s Settings
+	err := store.db.View(func(tx kv.Tx) error {
+		var err error
+		s, err = getSettings(tx, itob(id))
+		return err
+	})
+	if err != nil {
+		return s, fmt.Errorf("failed to get settings for %d: %v", id, err)
+	}
+	return s, nil
+}
+
+// SetSettings validates and saves the settings of a user.
+// New phrases are scheduled in case the limit has been increased.
+// Changing the scheduler converts the memory of all phrases like SetScheduler.
+func (store Store) SetSettings(id int64, s Settings) error {
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("failed to set settings for %d: %v", id, err)
+	}
+	err := store.db.Update(func(tx kv.Tx) error {
+		prefix := itob(id)
+		if err := setScheduler(tx, prefix, s.Scheduler); err != nil {
+			return err
+		}
+		// The scheduler is only kept in its own bucket
+		stored := s
+		stored.Scheduler = ""
+		var buf bytes.Buffer
+		if err := gob.NewEncoder(&buf).Encode(stored); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucket.Settings).Put(prefix, buf.Bytes()); err != nil {
+			return err
+		}
+		return updateZeroscore(tx, prefix, 0)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set settings for %d to %#v: %v", id, s, err)
+	}
+	return nil
+}
+
+// Get settings of a user, falls back to DefaultSettings.
+// Stored settings are decoded into zero settings,
+// since gob doesn't encode fields with zero values.
+func getSettings(tx kv.Tx, prefix []byte) (Settings, error) {
+	s := DefaultSettings
+	v := tx.Bucket(bucket.Settings).Get(prefix)
+	if v != nil {
+		s = Settings{}
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&s); err != nil {
+			return s, err
+		}
+	}
+	s.Scheduler, _ = getScheduler(tx, prefix)
+	return s, nil
+}
+
+// Get the time until the quiet hours in the given timezone are over.
+// Returns 0 if now is not in the quiet hours.
+func quietDelay(now time.Time, timezone float64, s Settings) time.Duration {
+	if s.QuietStart == s.QuietEnd {
+		return 0
+	}
+	local := now.UTC().Add(time.Duration(timezone * float64(time.Hour)))
+	h := local.Hour()
+	quiet := h >= s.QuietStart || h < s.QuietEnd
+	// Quiet hours don't span midnight
+	if s.QuietStart < s.QuietEnd {
+		quiet = h >= s.QuietStart && h < s.QuietEnd
+	}
+	if !quiet {
+		return 0
+	}
+	return time.Duration((s.QuietEnd-h+24)%24)*time.Hour - time.Duration(local.Minute())*time.Minute
+}
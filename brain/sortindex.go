@@ -0,0 +1,59 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Set the time a phrase has been added.
+// The added index is kept in sync with the add times.
+func setAddTime(tx kv.Tx, key []byte, t int64) error {
+	if err := deleteAddTime(tx, key); err != nil {
+		return err
+	}
+	if err := tx.Bucket(bucket.PhraseAddTimes).Put(key, itob(t)); err != nil {
+		return err
+	}
+	return tx.Bucket(bucket.AddedPhrases).Put(sortKey(key, t), []byte{})
+}
+
+// Remove the add time of a phrase and its entry in the added index.
+func deleteAddTime(tx kv.Tx, key []byte) error {
+	ba := tx.Bucket(bucket.PhraseAddTimes)
+	v := ba.Get(key)
+	if v == nil {
+		return nil
+	}
+	if err := tx.Bucket(bucket.AddedPhrases).Delete(sortKey(key, btoi(v))); err != nil {
+		return err
+	}
+	return ba.Delete(key)
+}
+
+// Move a phrase in the score index from the score it is stored with to score.
+func indexScore(tx kv.Tx, key []byte, score int) error {
+	bs := tx.Bucket(bucket.ScorePhrases)
+	if v := tx.Bucket(bucket.Phrases).Get(key); v != nil {
+		// Only the score is needed from the stored phrase
+		var prev struct{ Score int }
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&prev); err != nil {
+			return err
+		}
+		if prev.Score == score {
+			return nil
+		}
+		if err := bs.Delete(sortKey(key, int64(prev.Score))); err != nil {
+			return err
+		}
+	}
+	return bs.Put(sortKey(key, int64(score)), []byte{})
+}
+
+// Get the key of a phrase in an index sorted by value.
+// Same as for the due index, the value goes between user ID and phrase.
+func sortKey(key []byte, value int64) []byte {
+	return append(append(append([]byte{}, key[:8]...), itob(value)...), key[8:]...)
+}
@@ -2,12 +2,13 @@ package brain
 
 import (
 	"bytes"
+	"encoding/gob"
 	"fmt"
 	"io"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 const statmsg = "```" + `
@@ -30,12 +31,12 @@ new phrases: %8d (%d)
 // WriteStat writes plain text statistics for the whole DB to the given Writer.
 // The formatting is intended for markdown usage such as in Slack.
 func (store Store) WriteStat(w io.Writer) error {
-	return store.db.View(func(tx *bolt.Tx) error {
-		users := tx.Bucket(bucket.RegisterDates).Stats().KeyN
-		subscriptions := tx.Bucket(bucket.Subscriptions).Stats().KeyN
+	return store.db.View(func(tx kv.Tx) error {
+		users := tx.Bucket(bucket.RegisterDates).KeyN()
+		subscriptions := tx.Bucket(bucket.Subscriptions).KeyN()
 		dbSize := float64(tx.Size()) / 1024.0 / 1024.0 // in mb
 
-		phrasesTotal := tx.Bucket(bucket.Phrases).Stats().KeyN
+		phrasesTotal := tx.Bucket(bucket.Phrases).KeyN()
 		phrasesAvg := phrasesTotal / users
 
 		scoretotal, err := sum(tx.Bucket(bucket.Scoretotals), simplesum)
@@ -44,15 +45,16 @@ func (store Store) WriteStat(w io.Writer) error {
 		}
 		scoretotalAvg := scoretotal / users
 
-		studiesTotal := tx.Bucket(bucket.Studies).Stats().KeyN
+		studiesTotal := tx.Bucket(bucket.Studies).KeyN()
 		studiesAvg := studiesTotal / users
 
 		now := itob(time.Now().Unix())
-		dueStudiesTotal, err := sum(tx.Bucket(bucket.Studytimes), func(v []byte) int {
-			if bytes.Compare(v, now) < 1 {
-				return 1
+		dueStudiesTotal := 0
+		err = tx.Bucket(bucket.Studytimes).ForEach(func(k, v []byte) error {
+			if bytes.Compare(v, now) < 1 && !isSuspended(tx, k) {
+				dueStudiesTotal++
 			}
-			return 0
+			return nil
 		})
 		if err != nil {
 			return err
@@ -83,15 +85,49 @@ func (store Store) WriteStat(w io.Writer) error {
 		}
 		newphrasesAvg := newphrasesTotal / users
 
+		// Phrases studied in both directions have two cards, phrases with clozes one for each cloze
+		// Empty explanations are not indexed
+		cardsTotal := 0
+		explanationsTotal := 0
+		err = tx.Bucket(bucket.Phrases).ForEach(func(k, v []byte) error {
+			var p Phrase
+			if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+				return fmt.Errorf("gob decode phrase at %#v: %v", k, err)
+			}
+			cardsTotal += len(p.cards(k))
+			if p.Explanation != "" {
+				explanationsTotal++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
 		warnings := ""
-		notNewPhrases := phrasesTotal - newphrasesTotal
-		if n := tx.Bucket(bucket.Studytimes).Stats().KeyN; n != notNewPhrases {
-			warnings += fmt.Sprintf("\nWARNING: Number of studytimes (%d) does not match phrases - newphrases (%d).\n", n, notNewPhrases)
+		notNewCards := cardsTotal - newphrasesTotal
+		if n := tx.Bucket(bucket.Studytimes).KeyN(); n != notNewCards {
+			warnings += fmt.Sprintf("\nWARNING: Number of studytimes (%d) does not match cards - newphrases (%d).\n", n, notNewCards)
+		}
+		studytimes := tx.Bucket(bucket.Studytimes).KeyN()
+		if n := tx.Bucket(bucket.Dues).KeyN(); n != studytimes {
+			warnings += fmt.Sprintf("\nWARNING: Number of dues (%d) does not match number of studytimes (%d).\n", n, studytimes)
+		}
+		scoretotals := tx.Bucket(bucket.Scoretotals).KeyN()
+		if n := int(countRanks(tx.Bucket(bucket.Ranks), rankAll, rankMaxScore)); n != scoretotals {
+			warnings += fmt.Sprintf("\nWARNING: Number of ranked users (%d) does not match number of scoretotals (%d).\n", n, scoretotals)
 		}
-		if n := tx.Bucket(bucket.PhraseAddTimes).Stats().KeyN; n != phrasesTotal {
+		if n := tx.Bucket(bucket.Explanations).KeyN(); n != explanationsTotal {
+			warnings += fmt.Sprintf("\nWARNING: Number of explanations (%d) does not match number of phrases with explanation (%d).\n", n, explanationsTotal)
+		}
+		if n := tx.Bucket(bucket.PhraseAddTimes).KeyN(); n != phrasesTotal {
 			warnings += fmt.Sprintf("\nWARNING: Number of phraseaddtimes (%d) does not match number of phrases (%d).\n", n, phrasesTotal)
 		}
 
+		if warnings != "" {
+			warnings += "\nRun slangbrain-fsck for details.\n"
+		}
+
 		fmt.Fprintf(
 			w, statmsg, users, subscriptions, dbSize,
 			phrasesTotal, phrasesAvg,
@@ -109,7 +145,7 @@ func (store Store) WriteStat(w io.Writer) error {
 }
 
 // Sum all values in a bucket
-func sum(b *bolt.Bucket, fn func([]byte) int) (int, error) {
+func sum(b kv.Bucket, fn func([]byte) int) (int, error) {
 	sum := 0
 	err := b.ForEach(func(_, v []byte) error {
 		sum += fn(v)
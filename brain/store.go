@@ -2,31 +2,79 @@ package brain
 
 import (
 	"fmt"
+	"io/ioutil"
+	"log"
 	"math/rand"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+	"github.com/jorinvo/slangbrain/brain/migration"
 )
 
 // Store provides functions to interact with the underlying database.
 type Store struct {
-	db *bolt.DB
+	db        kv.DB
+	log       *log.Logger
+	noMigrate bool
 }
 
-// New returns a new Store with a database already setup.
-func New(dbFile string) (Store, error) {
-	db, err := bolt.Open(dbFile, 0600, &bolt.Options{Timeout: 1 * time.Second})
-	store := Store{db}
+// LogInfo is an option to set the logger for info messages like applied migrations.
+func LogInfo(l *log.Logger) func(*Store) {
+	return func(store *Store) {
+		store.log = l
+	}
+}
+
+// NoMigrate is an option to open a store without writing to the database,
+// for tools that only read a database or a backup of it.
+// Opening fails if the database has pending migrations.
+func NoMigrate(store *Store) {
+	store.noMigrate = true
+}
+
+// New returns a new Store with a Bolt database already setup.
+// Optionally pass LogInfo or NoMigrate.
+func New(dbFile string, options ...func(*Store)) (Store, error) {
+	db, err := kv.OpenBolt(dbFile, 0600)
 	if err != nil {
-		return store, fmt.Errorf("failed to open database: %v", err)
+		return Store{}, fmt.Errorf("failed to open database: %v", err)
+	}
+	return Open(db, options...)
+}
+
+// Open returns a new Store using an already opened database.
+// The database is setup if needed and closed together with the store.
+// Pending migrations are applied before anything else.
+// Optionally pass LogInfo or NoMigrate.
+func Open(db kv.DB, options ...func(*Store)) (Store, error) {
+	store := Store{db: db}
+	for _, option := range options {
+		option(&store)
 	}
+	if store.log == nil {
+		store.log = log.New(ioutil.Discard, "", 0)
+	}
+	if store.noMigrate {
+		err := db.View(func(tx kv.Tx) error {
+			if v := getVersion(tx); v != len(migration.All) {
+				return fmt.Errorf("database version %d doesn't match supported version %d", v, len(migration.All))
+			}
+			return nil
+		})
+		if err != nil {
+			return store, fmt.Errorf("failed to open database without migrating: %v", err)
+		}
+		return store, nil
+	}
+	err := db.Update(func(tx kv.Tx) error {
+		if _, err := migrate(tx, store.log); err != nil {
+			return err
+		}
 
-	err = db.Update(func(tx *bolt.Tx) error {
 		// Ensure buckets exist
 		for _, b := range bucket.All {
-			_, err = tx.CreateBucketIfNotExists(b)
-			if err != nil {
+			if _, err := tx.CreateBucketIfNotExists(b); err != nil {
 				return fmt.Errorf("failed to create bucket '%s': %v", b, err)
 			}
 		}
@@ -63,7 +111,7 @@ func (store *Store) Close() error {
 // TrackNotify sets the last time a notifications was sent to a user.
 func (store Store) TrackNotify(id int64, t time.Time) error {
 	key := itob(id)
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		if err := tx.Bucket(bucket.Activities).Put(key, itob(t.Unix())); err != nil {
 			return err
 		}
@@ -75,9 +123,28 @@ func (store Store) TrackNotify(id int64, t time.Time) error {
 	return nil
 }
 
+// HasUnreadNotify checks if the user hasn't read any message since the last notification.
+func (store Store) HasUnreadNotify(id int64) (bool, error) {
+	var unread bool
+	key := itob(id)
+	err := store.db.View(func(tx kv.Tx) error {
+		a := tx.Bucket(bucket.Activities).Get(key)
+		if a == nil {
+			return nil
+		}
+		r := tx.Bucket(bucket.Reads).Get(key)
+		unread = r == nil || btoi(r) <= btoi(a)
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check unread notification for %d: %v", id, err)
+	}
+	return unread, nil
+}
+
 // SetRead sets the last time the user read a message.
 func (store Store) SetRead(id int64, t time.Time) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Batch(func(tx kv.Tx) error {
 		return tx.Bucket(bucket.Reads).Put(itob(id), itob(t.Unix()))
 	})
 	if err != nil {
@@ -88,9 +155,22 @@ func (store Store) SetRead(id int64, t time.Time) error {
 
 // Register saves the date a user first started using the chatbot.
 // This is later on used for statistics.
+// Users with a score are moved to the rank of their register month.
 func (store Store) Register(id int64) error {
-	return store.db.Update(func(tx *bolt.Tx) error {
-		return tx.Bucket(bucket.RegisterDates).Put(itob(id), itob(time.Now().Unix()))
+	return store.db.Update(func(tx kv.Tx) error {
+		prefix := itob(id)
+		prevCohorts := rankCohorts(tx, prefix)[1:]
+		if err := tx.Bucket(bucket.RegisterDates).Put(prefix, itob(time.Now().Unix())); err != nil {
+			return err
+		}
+		if tx.Bucket(bucket.Scoretotals).Get(prefix) == nil {
+			return nil
+		}
+		score := getScoretotal(tx, prefix)
+		if err := moveRank(tx, prefix, prevCohorts, score, -1); err != nil {
+			return err
+		}
+		return moveRank(tx, prefix, rankCohorts(tx, prefix)[1:], -1, score)
 	})
 }
 
@@ -100,15 +180,14 @@ func (store Store) Register(id int64) error {
 // Should only be called with each messageID once.
 // Otherwise returns store.ErrExists.
 func (store Store) QueueMessage(messageID string) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Batch(func(tx kv.Tx) error {
 		b := tx.Bucket(bucket.MessageIDs)
 		key := []byte(messageID)
 		if b.Get(key) != nil {
 			return ErrExists
 		}
 
-		b.Put(key, itob(time.Now().Unix()))
-		return nil
+		return b.Put(key, itob(time.Now().Unix()))
 	})
 
 	if err != nil {
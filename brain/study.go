@@ -6,17 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"math/rand"
-	"sort"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // GetStudy returns the current study the user needs to do.
 func (store Store) GetStudy(id int64) (Study, error) {
 	var study Study
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
 		key, total, fromNow := findCurrentStudy(tx, itob(id), time.Now())
 
 		// No studies found
@@ -28,15 +27,35 @@ func (store Store) GetStudy(id int64) (Study, error) {
 		}
 
 		// Get study from phrase
-		p, err := getPhrase(tx, key)
+		pk, reverse := phraseKey(key)
+		p, err := getPhrase(tx, pk)
 		if err != nil {
 			return fmt.Errorf("cannot get phrase for key '%x' %#v: %v", key, key, err)
 		}
 		study = Study{
-			Phrase:      p.Phrase,
-			Explanation: p.Explanation,
-			Total:       total,
+			Phrase:       p.Phrase,
+			Alternatives: p.Alternatives,
+			Explanation:  p.Explanation,
+			Total:        total,
 		}
+		if reverse {
+			study = Study{
+				Phrase:      p.Explanation,
+				Explanation: p.Phrase,
+				Reverse:     true,
+				Total:       total,
+			}
+		}
+		if n := cardCloze(key); n > 0 {
+			question, answer := renderCloze(p.Phrase, n)
+			study = Study{
+				Phrase:      answer,
+				Explanation: question,
+				Cloze:       true,
+				Total:       total,
+			}
+		}
+		study.Card = btoi(key[8:])
 		return nil
 	})
 
@@ -46,9 +65,18 @@ func (store Store) GetStudy(id int64) (Study, error) {
 	return study, nil
 }
 
-// ScoreStudy sets the score of the current study and moves to the next study.
-func (store Store) ScoreStudy(id int64, scoreUpdate int) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+// ScoreStudy grades the current study and moves to the next study.
+// Each hint used for the study reduces the score update.
+// If the phrase has become a leech, its ID is returned.
+// Otherwise the returned ID is 0.
+func (store Store) ScoreStudy(id int64, grade Grade) (int64, error) {
+	scoreUpdate, ok := gradeScores[grade]
+	if !ok {
+		return 0, fmt.Errorf("failed to score study with id %d: invalid grade %d", id, grade)
+	}
+	var leech int64
+	err := store.db.Batch(func(tx kv.Tx) error {
+		leech = 0
 		now := time.Now()
 		prefix := itob(id)
 		key, _, _ := findCurrentStudy(tx, prefix, now)
@@ -58,70 +86,219 @@ func (store Store) ScoreStudy(id int64, scoreUpdate int) error {
 			return errors.New("no study found")
 		}
 
-		// Get phrase
-		p, err := getPhrase(tx, key)
+		// Get phrase and card
+		pk, _ := phraseKey(key)
+		p, err := getPhrase(tx, pk)
 		if err != nil {
 			return err
 		}
+		score, memory := p.card(key)
+
+		// Hints reduce the score update, but not below failing
+		hints := getHints(tx, prefix, key)
+		if err := tx.Bucket(bucket.Hints).Delete(prefix); err != nil {
+			return err
+		}
+		scoreUpdate := scoreUpdate - hints
+		if scoreUpdate < gradeScores[GradeAgain] {
+			scoreUpdate = gradeScores[GradeAgain]
+		}
 
 		// Update score
-		prevScore := p.Score
-		p.Score += scoreUpdate
-		if p.Score < 0 {
-			p.Score = 0
+		prevScore, prevMemory := *score, *memory
+		*score += scoreUpdate
+		if *score < 0 {
+			*score = 0
 		}
 
 		// Update zeroscore
-		if prevScore == 0 && p.Score > 0 {
+		if prevScore == 0 && *score > 0 {
 			if err := updateZeroscore(tx, prefix, -1); err != nil {
 				return err
 			}
-		} else if prevScore > 0 && p.Score == 0 {
+		} else if prevScore > 0 && *score == 0 {
 			if err := updateZeroscore(tx, prefix, 1); err != nil {
 				return err
 			}
 		}
 
 		// Update scoretotal
-		if err := addCountToBucket(tx.Bucket(bucket.Scoretotals), prefix, p.Score-prevScore); err != nil {
+		if err := updateScoretotal(tx, prefix, *score-prevScore); err != nil {
+			return err
+		}
+
+		// Let the scheduler of the user decide when to study the card next.
+		// Schedulers work with score and memory of the phrase,
+		// so pass a copy of the phrase with the values of the card.
+		bs := tx.Bucket(bucket.Studytimes)
+		elapsed := now.Sub(memory.Last)
+		if memory.Last.IsZero() {
+			// Before the time of the last study has been stored,
+			// the interval included multiplier and diffusion and ended at the study time.
+			elapsed = memory.Interval
+			if v := bs.Get(key); v != nil {
+				elapsed += now.Sub(time.Unix(btoi(v), 0))
+			}
+		}
+		_, scheduler := getScheduler(tx, prefix)
+		settings, err := getSettings(tx, prefix)
+		if err != nil {
 			return err
 		}
+		c := Phrase{Phrase: p.Phrase, Explanation: p.Explanation, Score: *score, Memory: *memory}
+		// Keep the interval of the scheduler in the memory,
+		// otherwise the multiplier would compound with each study.
+		c.Memory.Interval = scheduler.Next(&c, grade, elapsed)
+		c.Memory.Last = now
+		*memory = c.Memory
+		interval := diffusion(time.Duration(float64(memory.Interval) * settings.IntervalMultiplier))
+
+		// Check for leech, the current study is not saved yet
+		prevLeech := p.Leech
+		if grade == GradeAgain && (countFailures(tx, key)+1)%leechThreshold == 0 {
+			p.Leech = true
+			leech = btoi(pk[8:])
+		}
 
 		// Save phrase
+		if err := putPhrase(tx, pk, p); err != nil {
+			return err
+		}
+
+		// Studies are identified by time and a sequence,
+		// so studies in the same second don't overwrite each other
+		seq, err := tx.Bucket(bucket.Studies).NextSequence()
+		if err != nil {
+			return err
+		}
+		studyID := append(itob(now.Unix()), itob(int64(seq))...)
+
+		// Remember the previous state of the card to be able to undo the study
+		prevTime := bs.Get(key)
+		if prevTime == nil {
+			prevTime = itob(now.Unix())
+		}
 		var buf bytes.Buffer
-		if err := gob.NewEncoder(&buf).Encode(p); err != nil {
+		if err := gob.NewEncoder(&buf).Encode(prevMemory); err != nil {
 			return err
 		}
-		if err := tx.Bucket(bucket.Phrases).Put(key, buf.Bytes()); err != nil {
+		var leechFlag int64
+		if prevLeech {
+			leechFlag = 1
+		}
+		undo := append(append(append(append(append(append([]byte{}, studyID...), key[8:]...), itob(int64(prevScore))...), prevTime...), itob(leechFlag)...), buf.Bytes()...)
+		if err := tx.Bucket(bucket.Undos).Put(prefix, undo); err != nil {
 			return err
 		}
 
-		// Update study time
-		i := p.Score
-		if i >= len(studyIntervals) {
-			i = len(studyIntervals) - 1
+		if err := updateStreak(tx, prefix, now); err != nil {
+			return err
 		}
-		next := itob(now.Add(diffusion(studyIntervals[i])).Unix())
-		if err := tx.Bucket(bucket.Studytimes).Put(key, next); err != nil {
+
+		// Update study time
+		next := itob(now.Add(interval).Unix())
+		if err := setStudytime(tx, key, btoi(next)); err != nil {
 			return err
 		}
 
-		fmt.Printf("phrase: %s; prev score: %v; new score: %v; update: %v; next study: %v\n", p.Phrase, prevScore, p.Score, scoreUpdate, time.Unix(btoi(next), 0).Sub(now))
+		fmt.Printf("phrase: %s; prev score: %v; new score: %v; grade: %v; next study: %v\n", p.Phrase, prevScore, *score, grade, time.Unix(btoi(next), 0).Sub(now))
 
 		// Save study for reference and to analyze them later
-		idAndTime := append(append([]byte{}, prefix...), itob(now.Unix())...)
-		seqAndScores := append(append(append([]byte{}, key[8:]...), itob(int64(scoreUpdate))...), itob(int64(p.Score))...)
-		if err := tx.Bucket(bucket.Studies).Put(idAndTime, seqAndScores); err != nil {
+		idAndStudy := append(append([]byte{}, prefix...), studyID...)
+		seqAndScores := append(append(append([]byte{}, key[8:]...), itob(int64(scoreUpdate))...), itob(int64(*score))...)
+		gradeAndHints := append(itob(int64(grade)), itob(int64(hints))...)
+		study := append(seqAndScores, gradeAndHints...)
+		if err := tx.Bucket(bucket.Studies).Put(idAndStudy, study); err != nil {
 			return err
 		}
-
-		return nil
+		return tx.Bucket(bucket.PhraseStudies).Put(phraseStudyKey(key, studyID), study)
 	})
 
 	if err != nil {
-		return fmt.Errorf("failed to score study with id %d: %v", id, err)
+		return 0, fmt.Errorf("failed to score study with id %d: %v", id, err)
+	}
+	return leech, nil
+}
+
+// UndoLastStudy reverts the last study of a user.
+// Score, memory and study time of the studied card
+// and the leech flag of its phrase are restored
+// and the study is removed from the history.
+// Only the last study can be undone.
+// New phrases that have been scheduled because of the study stay scheduled.
+// Returns ErrNotFound if there is no study to undo
+// or if the card has been removed since.
+func (store Store) UndoLastStudy(id int64) error {
+	err := store.db.Update(func(tx kv.Tx) error {
+		prefix := itob(id)
+		bu := tx.Bucket(bucket.Undos)
+		v := bu.Get(prefix)
+		if v == nil {
+			return ErrNotFound
+		}
+		// Copy, since the value is invalid after deleting it
+		v = append([]byte{}, v...)
+		if err := bu.Delete(prefix); err != nil {
+			return err
+		}
+
+		studyID := v[:16]
+		key := append(append([]byte{}, prefix...), v[16:24]...)
+		prevScore := int(btoi(v[24:32]))
+		prevTime := v[32:40]
+		prevLeech := btoi(v[40:48]) == 1
+		var prevMemory Memory
+		if err := gob.NewDecoder(bytes.NewReader(v[48:])).Decode(&prevMemory); err != nil {
+			return err
+		}
+
+		// Card might have been removed or suspended since
+		bs := tx.Bucket(bucket.Studytimes)
+		if bs.Get(key) == nil || isSuspended(tx, key) {
+			return ErrNotFound
+		}
+		pk, _ := phraseKey(key)
+		p, err := getPhrase(tx, pk)
+		if err != nil {
+			return err
+		}
+		score, memory := p.card(key)
+
+		// Update zeroscore
+		if *score == 0 && prevScore > 0 {
+			if err := updateZeroscore(tx, prefix, -1); err != nil {
+				return err
+			}
+		} else if *score > 0 && prevScore == 0 {
+			if err := updateZeroscore(tx, prefix, 1); err != nil {
+				return err
+			}
+		}
+
+		// Update scoretotal
+		if err := updateScoretotal(tx, prefix, prevScore-*score); err != nil {
+			return err
+		}
+
+		*score, *memory = prevScore, prevMemory
+		p.Leech = prevLeech
+		if err := putPhrase(tx, pk, p); err != nil {
+			return err
+		}
+		if err := setStudytime(tx, key, btoi(prevTime)); err != nil {
+			return err
+		}
+
+		if err := tx.Bucket(bucket.PhraseStudies).Delete(phraseStudyKey(key, studyID)); err != nil {
+			return err
+		}
+		return tx.Bucket(bucket.Studies).Delete(append(append([]byte{}, prefix...), studyID...))
+	})
+
+	if err != nil && err != ErrNotFound {
+		return fmt.Errorf("failed to undo last study of %d: %v", id, err)
 	}
-	return nil
+	return err
 }
 
 // Randomize order by spreading studies over a period of time
@@ -133,52 +310,48 @@ func diffusion(t time.Duration) time.Duration {
 // Returns the time until the next studies are ready and a count of the ready studies.
 // The returned duration is always at least dueMinInactive.
 // The count is 0 if the chat has no phrases yet.
-// The returned duration gets delayed if it would be in a user's night time.
-// Nighttime is calculated form the passed timezone.
+// The returned duration gets delayed if it would be in a user's quiet hours.
+// Quiet hours are calculated form the passed timezone.
+// Quiet hours and the minimum number of due studies are taken from the user's settings.
 func (store Store) GetNotifyTime(id int64, timezone float64) (time.Duration, int, error) {
 	due := 0
 	now := time.Now()
-
-	// Delay if night
 	var delay time.Duration
-	userHour := float64(now.Add(delay).UTC().Hour()) + timezone
-	if userHour > nightStart {
-		delay = time.Duration(24-userHour+nightEnd)*time.Hour + time.Duration(60-now.Minute())*time.Minute
-	} else if userHour < nightEnd {
-		delay = time.Duration(nightEnd-userHour)*time.Hour - time.Duration(now.Minute())*time.Minute
-	}
-	// Ensure minimum delay
-	if delay < dueMinInactive {
-		delay = dueMinInactive
-	}
-
-	minTime := now.Add(delay).Unix()
-	var nexts sortableInts
+	var minCount int
+	var nexts []int64
 
-	err := store.db.View(func(tx *bolt.Tx) error {
-		c := tx.Bucket(bucket.Studytimes).Cursor()
+	err := store.db.View(func(tx kv.Tx) error {
+		c := tx.Bucket(bucket.Dues).Cursor()
 		prefix := itob(id)
+		settings, err := getSettings(tx, prefix)
+		if err != nil {
+			return err
+		}
+		minCount = settings.NotifyMinCount
 
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			timestamp := btoi(v)
-			if timestamp < minTime {
-				due++
+		// Delay if quiet hours
+		delay = quietDelay(now, timezone, settings)
+		// Ensure minimum delay
+		if delay < dueMinInactive {
+			delay = dueMinInactive
+		}
+		minTime := now.Add(delay).Unix()
+
+		// The due index is ordered by time,
+		// stop once all due studies are counted and enough next studies are found.
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			timestamp := btoi(k[8:16])
+			if timestamp >= minTime && len(nexts) >= minCount {
+				break
 			}
-
-			if due >= dueMinCount {
+			if isSuspended(tx, dueCard(k)) {
 				continue
 			}
-
-			l := len(nexts)
-			if l < dueMinCount {
-				nexts = append(nexts, timestamp)
-				sort.Sort(nexts)
-				continue
+			if timestamp < minTime {
+				due++
 			}
-
-			if timestamp < nexts[l-1] {
-				nexts = append(nexts[:l-1], timestamp)
-				sort.Sort(nexts)
+			if len(nexts) < minCount {
+				nexts = append(nexts, timestamp)
 			}
 		}
 
@@ -190,7 +363,6 @@ func (store Store) GetNotifyTime(id int64, timezone float64) (time.Duration, int
 	}
 
 	// If user has too little phrases, minCount is ignored
-	minCount := dueMinCount
 	l := len(nexts)
 	if minCount > l {
 		minCount = l
@@ -207,7 +379,7 @@ func (store Store) GetNotifyTime(id int64, timezone float64) (time.Duration, int
 // EachActiveChat runs a function for each chat
 // where the user has been active since the last notification has been sent.
 func (store Store) EachActiveChat(fn func(int64)) error {
-	return store.db.View(func(tx *bolt.Tx) error {
+	return store.db.View(func(tx kv.Tx) error {
 		active := tx.Bucket(bucket.Activities)
 		return tx.Bucket(bucket.Reads).ForEach(func(k, v []byte) error {
 			a := active.Get(k)
@@ -223,17 +395,3 @@ func (store Store) EachActiveChat(fn func(int64)) error {
 		})
 	})
 }
-
-type sortableInts []int64
-
-func (b sortableInts) Len() int {
-	return len(b)
-}
-
-func (b sortableInts) Less(i, j int) bool {
-	return b[i] < b[j]
-}
-
-func (b sortableInts) Swap(i, j int) {
-	b[j], b[i] = b[i], b[j]
-}
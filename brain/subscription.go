@@ -3,14 +3,14 @@ package brain
 import (
 	"fmt"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // IsSubscribed checks if a user has notifications enabled.
 func (store Store) IsSubscribed(id int64) (bool, error) {
 	var isSubscribed bool
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
 		isSubscribed = tx.Bucket(bucket.Subscriptions).Get(itob(id)) != nil
 		return nil
 	})
@@ -22,7 +22,7 @@ func (store Store) IsSubscribed(id int64) (bool, error) {
 
 // Subscribe enables notifications for a user.
 func (store Store) Subscribe(id int64) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		return tx.Bucket(bucket.Subscriptions).Put(itob(id), []byte{'1'})
 	})
 	if err != nil {
@@ -33,7 +33,7 @@ func (store Store) Subscribe(id int64) error {
 
 // Unsubscribe disables notifications for a user.
 func (store Store) Unsubscribe(id int64) error {
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		return tx.Bucket(bucket.Subscriptions).Delete(itob(id))
 	})
 	if err != nil {
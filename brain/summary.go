@@ -0,0 +1,156 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// GetSummary summarizes the most recent study session of a user.
+// A session ends when there has been no study for sessionMaxPause.
+func (store Store) GetSummary(id int64) (Summary, error) {
+	var s Summary
+	err := store.db.View(func(tx kv.Tx) error {
+		prefix := itob(id)
+		failures := map[string]int{}
+		learned := map[string]bool{}
+		var prev int64
+
+		c := tx.Bucket(bucket.Studies).Cursor()
+		for k, v := lastStudy(c, prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Prev() {
+			t := btoi(k[8:16])
+			if prev != 0 && time.Duration(prev-t)*time.Second > sessionMaxPause {
+				break
+			}
+			prev = t
+			card := string(v[:8])
+
+			s.Studied++
+			if studyFailed(v) {
+				failures[card]++
+			} else {
+				s.Correct++
+			}
+			// Score has been increased from 0
+			if newScore := btoi(v[16:24]); newScore > 0 && newScore == btoi(v[8:16]) {
+				learned[card] = true
+			}
+		}
+		s.Learned = len(learned)
+
+		hardest, err := hardestPhrases(tx, prefix, failures)
+		if err != nil {
+			return err
+		}
+		s.Hardest = hardest
+		s.Streak = getStreak(tx, prefix, time.Now())
+		return nil
+	})
+	if err != nil {
+		return s, fmt.Errorf("failed to get summary for %d: %v", id, err)
+	}
+	return s, nil
+}
+
+// Get the phrases of the cards failed most often.
+// Phrases with clozes are shown without markup.
+func hardestPhrases(tx kv.Tx, prefix []byte, failures map[string]int) ([]string, error) {
+	cards := failedCards{failures: failures}
+	for card := range failures {
+		cards.ids = append(cards.ids, card)
+	}
+	sort.Sort(cards)
+
+	var phrases []string
+	bp := tx.Bucket(bucket.Phrases)
+	for _, card := range cards.ids {
+		if len(phrases) == summaryHardest {
+			break
+		}
+		pk, _ := phraseKey(append(append([]byte{}, prefix...), card...))
+		v := bp.Get(pk)
+		// Phrase might have been deleted since
+		if v == nil {
+			continue
+		}
+		var p Phrase
+		if err := gob.NewDecoder(bytes.NewReader(v)).Decode(&p); err != nil {
+			return nil, fmt.Errorf("failed to decode phrase %x: %v", pk, err)
+		}
+		text, _ := renderCloze(p.Phrase, 0)
+		phrases = append(phrases, text)
+	}
+	return phrases, nil
+}
+
+// Card IDs sorted by their number of failures.
+// Cards with the same number of failures are sorted by ID to have a stable order.
+type failedCards struct {
+	ids      []string
+	failures map[string]int
+}
+
+func (c failedCards) Len() int {
+	return len(c.ids)
+}
+
+func (c failedCards) Less(i, j int) bool {
+	a, b := c.failures[c.ids[i]], c.failures[c.ids[j]]
+	if a != b {
+		return a > b
+	}
+	return c.ids[i] < c.ids[j]
+}
+
+func (c failedCards) Swap(i, j int) {
+	c.ids[j], c.ids[i] = c.ids[i], c.ids[j]
+}
+
+// Count the day of now into the streak of the user.
+// The streak continues if the user studied the day before, otherwise it starts again.
+func updateStreak(tx kv.Tx, prefix []byte, now time.Time) error {
+	b := tx.Bucket(bucket.Streaks)
+	today := localDay(now, getTimezone(tx, prefix))
+	streak := int64(1)
+	if v := b.Get(prefix); v != nil {
+		switch day := btoi(v[:8]); day {
+		case today:
+			return nil
+		case today - 1:
+			streak = btoi(v[8:]) + 1
+		}
+	}
+	return b.Put(prefix, append(itob(today), itob(streak)...))
+}
+
+// Get the current streak of a user.
+// Streaks are over if the user hasn't studied yesterday or today.
+func getStreak(tx kv.Tx, prefix []byte, now time.Time) int {
+	v := tx.Bucket(bucket.Streaks).Get(prefix)
+	if v == nil || btoi(v[:8]) < localDay(now, getTimezone(tx, prefix))-1 {
+		return 0
+	}
+	return int(btoi(v[8:]))
+}
+
+// Get the number of days since the unix epoch in the given timezone.
+func localDay(now time.Time, timezone float64) int64 {
+	offset := time.Duration(timezone * float64(time.Hour))
+	return int64(time.Duration(now.Unix())*time.Second+offset) / int64(24*time.Hour)
+}
+
+// Get the timezone from the cached profile of a user.
+// Defaults to UTC if there is no profile.
+func getTimezone(tx kv.Tx, prefix []byte) float64 {
+	var p profileData
+	v := tx.Bucket(bucket.Profiles).Get(prefix)
+	if v == nil || gob.NewDecoder(bytes.NewReader(v)).Decode(&p) != nil {
+		return 0
+	}
+	return p.Timezone
+}
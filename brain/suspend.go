@@ -0,0 +1,142 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// SuspendPhrase excludes a phrase from studies until it is unsuspended.
+// Score and study times of the phrase are kept.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) SuspendPhrase(id int64, seq int, suspend bool) error {
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		p, err := getPhrase(tx, key)
+		if err != nil {
+			return err
+		}
+		b := tx.Bucket(bucket.Suspended)
+		if (b.Get(key) != nil) == suspend {
+			return nil
+		}
+
+		// Update index before zeroscore,
+		// so new cards of a suspended phrase are not scheduled.
+		if suspend {
+			err = b.Put(key, []byte{})
+		} else {
+			err = b.Delete(key)
+		}
+		if err != nil {
+			return err
+		}
+
+		// Suspended cards don't count as zeroscore,
+		// otherwise they would block new phrases forever.
+		update := 1
+		if suspend {
+			update = -1
+		}
+		bs := tx.Bucket(bucket.Studytimes)
+		for _, card := range p.cards(key) {
+			score, _ := p.card(card)
+			if bs.Get(card) == nil || *score != 0 {
+				continue
+			}
+			if err := updateZeroscore(tx, key[:8], update); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to set suspended for key %x to %t: %v", key, suspend, err)
+	}
+	return err
+}
+
+// BuryPhrase postpones all studies of a phrase until the next day.
+// The timezone is used to find the start of the user's next day.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) BuryPhrase(id int64, seq int, timezone float64) error {
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		p, err := getPhrase(tx, key)
+		if err != nil {
+			return err
+		}
+		bs := tx.Bucket(bucket.Studytimes)
+		tomorrow := startOfNextDay(time.Now(), timezone).Unix()
+		for _, card := range p.cards(key) {
+			// Only scheduled cards can be buried
+			v := bs.Get(card)
+			if v == nil || btoi(v) >= tomorrow {
+				continue
+			}
+			if err := setStudytime(tx, card, tomorrow); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to bury phrase for key %x: %v", key, err)
+	}
+	return err
+}
+
+// Checks if the phrase a card belongs to is suspended.
+func isSuspended(tx kv.Tx, card []byte) bool {
+	pk, _ := phraseKey(card)
+	return tx.Bucket(bucket.Suspended).Get(pk) != nil
+}
+
+// Get midnight of the day after now in the given timezone.
+func startOfNextDay(now time.Time, timezone float64) time.Time {
+	offset := time.Duration(timezone * float64(time.Hour))
+	y, m, d := now.UTC().Add(offset).Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC).Add(-offset)
+}
+
+// Count how often a card has been failed in a row, starting with the most recent study.
+// Only the studies of the card's phrase are read from the phrase studies index.
+func countFailures(tx kv.Tx, card []byte) int {
+	pk, _ := phraseKey(card)
+	c := tx.Bucket(bucket.PhraseStudies).Cursor()
+	failures := 0
+	for k, v := lastStudy(c, pk); k != nil && bytes.HasPrefix(k, pk); k, v = c.Prev() {
+		if !bytes.Equal(v[:8], card[8:]) {
+			continue
+		}
+		if !studyFailed(v) {
+			break
+		}
+		failures++
+	}
+	return failures
+}
+
+// Move the cursor to the last study with the given prefix.
+// The prefix is a user ID or the key of a phrase.
+// Can be used to go through the studies backwards.
+func lastStudy(c kv.Cursor, prefix []byte) ([]byte, []byte) {
+	last := len(prefix) - 8
+	k, _ := c.Seek(append(append([]byte{}, prefix[:last]...), itob(btoi(prefix[last:])+1)...))
+	if k == nil {
+		return c.Last()
+	}
+	return c.Prev()
+}
+
+// Check if a study from the Studies bucket has been failed.
+// Studies before grades have been introduced count as failed if their score decreased.
+func studyFailed(v []byte) bool {
+	if len(v) >= 32 {
+		return Grade(btoi(v[24:32])) == GradeAgain
+	}
+	return btoi(v[8:16]) < 0
+}
@@ -0,0 +1,125 @@
+package brain
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// GetTags returns all tags a user has used, sorted by name.
+func (store Store) GetTags(id int64) ([]string, error) {
+	var tags []string
+	err := store.db.View(func(tx kv.Tx) error {
+		c := tx.Bucket(bucket.Tags).Cursor()
+		prefix := itob(id)
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			tag := string(k[8 : len(k)-9])
+			// Index is sorted, so only need to compare with the previous one
+			if l := len(tags); l == 0 || tags[l-1] != tag {
+				tags = append(tags, tag)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return tags, fmt.Errorf("failed to get tags for %d: %v", id, err)
+	}
+	return tags, nil
+}
+
+// GetPhrasesByTag returns all phrases of a user with the given tag,
+// sorted by the time they have been added.
+func (store Store) GetPhrasesByTag(id int64, tag string) ([]IDPhrase, error) {
+	phrases, _, err := store.QueryPhrases(id, PhraseQuery{Tag: tag})
+	if err != nil {
+		return phrases, fmt.Errorf("failed to get phrases with tag '%s' for %d: %v", tag, id, err)
+	}
+	return phrases, nil
+}
+
+// SetTags replaces the tags of a phrase.
+// Returns ErrNotFound if phrase doesn't exist.
+func (store Store) SetTags(id int64, seq int, tags []string) error {
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		p, err := getPhrase(tx, key)
+		if err != nil {
+			return err
+		}
+		tags = normTags(tags)
+		if err := indexTags(tx, key, p.Tags, tags); err != nil {
+			return err
+		}
+		p.Tags = tags
+		return putPhrase(tx, key, p)
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to set tags for key %x to %v: %v", key, tags, err)
+	}
+	return err
+}
+
+// SetStudyTag limits studies of a user to phrases with the given tag.
+// Pass an empty tag to study all phrases.
+func (store Store) SetStudyTag(id int64, tag string) error {
+	err := store.db.Update(func(tx kv.Tx) error {
+		b := tx.Bucket(bucket.StudyTags)
+		if tag == "" {
+			return b.Delete(itob(id))
+		}
+		return b.Put(itob(id), []byte(tag))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set study tag for %d to '%s': %v", id, tag, err)
+	}
+	return nil
+}
+
+// Key in the tags index is id+tag+0+phrase.
+// The zero byte separates the tag from the phrase ID
+// so a tag is never the prefix of another tag.
+func tagKey(prefix []byte, tag string, phraseID []byte) []byte {
+	k := append(append(append([]byte{}, prefix...), tag...), 0)
+	return append(k, phraseID...)
+}
+
+// Checks if the phrase a card belongs to has a tag.
+func hasTag(tx kv.Tx, card []byte, tag []byte) bool {
+	pk, _ := phraseKey(card)
+	return tx.Bucket(bucket.Tags).Get(tagKey(pk[:8], string(tag), pk[8:])) != nil
+}
+
+// Update the tags index for a phrase from prev to next tags.
+func indexTags(tx kv.Tx, key []byte, prev, next []string) error {
+	b := tx.Bucket(bucket.Tags)
+	for _, tag := range prev {
+		if err := b.Delete(tagKey(key[:8], tag, key[8:])); err != nil {
+			return err
+		}
+	}
+	for _, tag := range next {
+		if err := b.Put(tagKey(key[:8], tag, key[8:]), []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Trim tags, remove empty ones and duplicates.
+// Zero bytes are not allowed, since they are used as separator in the index.
+func normTags(tags []string) []string {
+	var norm []string
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.Replace(tag, "\x00", "", -1))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		norm = append(norm, tag)
+	}
+	return norm
+}
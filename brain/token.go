@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // GenerateToken creates and returns the token for a user.
@@ -16,7 +16,7 @@ import (
 // There is no way to expire tokens for now, because links should be shareable and token should be useable for automation.
 func (store Store) GenerateToken(id int64) (string, error) {
 	var token string
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		bid := itob(id)
 		bu := tx.Bucket(bucket.AuthUsers)
 		bt := tx.Bucket(bucket.AuthTokens)
@@ -51,7 +51,7 @@ func (store Store) GenerateToken(id int64) (string, error) {
 // Returns ErrNotFound if token is invalid.
 func (store Store) LookupToken(token string) (int64, error) {
 	var id int64
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
 		i := tx.Bucket(bucket.AuthTokens).Get([]byte(token))
 		if i == nil {
 			return ErrNotFound
@@ -0,0 +1,196 @@
+package brain
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// TrashedPhrase is a deleted phrase that can still be restored.
+type TrashedPhrase struct {
+	IDPhrase
+	Deleted int64 `json:"deleted"`
+}
+
+// The state of a deleted phrase needed to restore it.
+type trashed struct {
+	Phrase    Phrase
+	Added     int64
+	Suspended bool
+	// Study time of each card in the order of Phrase.cards.
+	// Cards that were new have no study time.
+	Studytimes []int64
+}
+
+// Save the state of a phrase to the trash before it is deleted.
+func trashPhrase(tx kv.Tx, key []byte, deletedAt time.Time) error {
+	p, err := getPhrase(tx, key)
+	if err != nil {
+		return err
+	}
+	t := trashed{Phrase: p, Suspended: tx.Bucket(bucket.Suspended).Get(key) != nil}
+	if v := tx.Bucket(bucket.PhraseAddTimes).Get(key); v != nil {
+		t.Added = btoi(v)
+	}
+	bs := tx.Bucket(bucket.Studytimes)
+	for _, card := range p.cards(key) {
+		var studytime int64
+		if v := bs.Get(card); v != nil {
+			studytime = btoi(v)
+		}
+		t.Studytimes = append(t.Studytimes, studytime)
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t); err != nil {
+		return err
+	}
+	return tx.Bucket(bucket.Trash).Put(key, append(itob(deletedAt.Unix()), buf.Bytes()...))
+}
+
+// Decode a trash entry and its deletion time.
+func getTrashed(v []byte) (trashed, int64, error) {
+	var t trashed
+	if err := gob.NewDecoder(bytes.NewReader(v[8:])).Decode(&t); err != nil {
+		return t, 0, err
+	}
+	return t, btoi(v[:8]), nil
+}
+
+// GetTrash returns the deleted phrases of a user, most recently deleted first.
+func (store Store) GetTrash(id int64) ([]TrashedPhrase, error) {
+	var phrases []TrashedPhrase
+	prefix := itob(id)
+	err := store.db.View(func(tx kv.Tx) error {
+		c := tx.Bucket(bucket.Trash).Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			t, deleted, err := getTrashed(v)
+			if err != nil {
+				return err
+			}
+			p := t.Phrase
+			phrases = append(phrases, TrashedPhrase{
+				IDPhrase{btoi(k[8:]), p.Phrase, p.Explanation, p.Score, t.Added, p.Direction, p.ReverseScore, p.Alternatives, p.Tags, t.Suspended, p.Leech},
+				deleted,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash for %d: %v", id, err)
+	}
+	// Sort by deletion time, newest first; phrases deleted at once stay in ID order
+	sort.SliceStable(phrases, func(i, j int) bool {
+		return phrases[i].Deleted > phrases[j].Deleted
+	})
+	return phrases, nil
+}
+
+// RestorePhrase moves a deleted phrase back from the trash.
+// Scores, memories and study times of its cards are restored.
+// Cards that were new are queued as new phrases again.
+// Returns ErrNotFound if phrase is not in the trash.
+func (store Store) RestorePhrase(id int64, seq int) error {
+	key := append(itob(id), itob(int64(seq))...)
+	err := store.db.Update(func(tx kv.Tx) error {
+		bt := tx.Bucket(bucket.Trash)
+		v := bt.Get(key)
+		if v == nil {
+			return ErrNotFound
+		}
+		t, _, err := getTrashed(v)
+		if err != nil {
+			return err
+		}
+		if err := bt.Delete(key); err != nil {
+			return err
+		}
+		p := t.Phrase
+
+		// Save phrase and indexes
+		if err := putPhrase(tx, key, p); err != nil {
+			return err
+		}
+		if err := indexTags(tx, key, nil, p.Tags); err != nil {
+			return err
+		}
+		if err := indexExplanation(tx, key, "", p.Explanation); err != nil {
+			return err
+		}
+		if err := setAddTime(tx, key, t.Added); err != nil {
+			return err
+		}
+		// Restore suspension before cards,
+		// so zeroscore and scheduling of new phrases see it.
+		if t.Suspended {
+			if err := tx.Bucket(bucket.Suspended).Put(key, []byte{}); err != nil {
+				return err
+			}
+		}
+
+		// Reverse what removeCard did on delete
+		for i, card := range p.cards(key) {
+			score, _ := p.card(card)
+			if i >= len(t.Studytimes) || t.Studytimes[i] == 0 {
+				if err := queueCard(tx, card, time.Now().Add(studyIntervals[0])); err != nil {
+					return err
+				}
+			} else {
+				if err := setStudytime(tx, card, t.Studytimes[i]); err != nil {
+					return err
+				}
+				if *score == 0 && !t.Suspended {
+					if err := updateZeroscore(tx, key[:8], 1); err != nil {
+						return err
+					}
+				}
+			}
+			if err := updateScoretotal(tx, key[:8], *score); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil && err != ErrNotFound {
+		err = fmt.Errorf("failed to restore phrase for key %x: %v", key, err)
+	}
+	return err
+}
+
+// PurgeTrash permanently removes all phrases deleted before the given time.
+// Returns the number of purged phrases.
+func (store Store) PurgeTrash(before time.Time) (int, error) {
+	var purged int
+	err := store.db.Update(func(tx kv.Tx) error {
+		purged = 0
+		bt := tx.Bucket(bucket.Trash)
+		var keys [][]byte
+		err := bt.ForEach(func(k, v []byte) error {
+			if btoi(v[:8]) < before.Unix() {
+				keys = append(keys, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := bt.Delete(k); err != nil {
+				return err
+			}
+			if err := deletePhraseStudies(tx, k); err != nil {
+				return err
+			}
+			purged++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash before %v: %v", before, err)
+	}
+	return purged, nil
+}
@@ -0,0 +1,225 @@
+package brain
+
+import (
+	"sync"
+
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// Update runs fn with a Store whose methods all share a single writable transaction.
+// The changes are only saved once fn returns without error
+// and none of the writes of the methods called by fn failed.
+// Each method rolls back its own changes when it returns an error, same as its own transaction would.
+// Writes failing with ErrNotFound, ErrExists or ErrNotReady
+// signal an expected state and don't abort the transaction.
+// The Store passed to fn must not be used concurrently while fn runs.
+// It can be kept after fn returned and then uses the database directly again.
+func (store Store) Update(fn func(Store) error) error {
+	t := &txDB{db: store.db}
+	return store.db.Update(func(tx kv.Tx) error {
+		t.set(tx)
+		defer t.set(nil)
+		if err := fn(Store{db: t, log: store.log}); err != nil {
+			return err
+		}
+		return t.failed()
+	})
+}
+
+// txDB runs all transactions in tx while it is set and uses db otherwise.
+// Nested transactions simply continue the outer one.
+// The first error of a nested write is kept to abort the outer transaction.
+type txDB struct {
+	db  kv.DB
+	mu  sync.Mutex
+	tx  kv.Tx
+	err error
+}
+
+func (t *txDB) set(tx kv.Tx) {
+	t.mu.Lock()
+	t.tx = tx
+	t.mu.Unlock()
+}
+
+func (t *txDB) get() kv.Tx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tx
+}
+
+func (t *txDB) View(fn func(kv.Tx) error) error {
+	if tx := t.get(); tx != nil {
+		return fn(tx)
+	}
+	return t.db.View(fn)
+}
+
+func (t *txDB) Update(fn func(kv.Tx) error) error {
+	if tx := t.get(); tx != nil {
+		return t.nested(tx, fn)
+	}
+	return t.db.Update(fn)
+}
+
+func (t *txDB) Batch(fn func(kv.Tx) error) error {
+	if tx := t.get(); tx != nil {
+		return t.nested(tx, fn)
+	}
+	return t.db.Batch(fn)
+}
+
+// Run a nested write and roll back its changes if it fails.
+func (t *txDB) nested(tx kv.Tx, fn func(kv.Tx) error) error {
+	sp := &savepoint{tx: tx}
+	err := fn(sp)
+	if err != nil {
+		if rerr := sp.rollback(); rerr != nil {
+			t.fail(rerr)
+		}
+	}
+	return t.fail(err)
+}
+
+// Remember the error of a write that might have left partial changes.
+func (t *txDB) fail(err error) error {
+	if err != nil && err != ErrNotFound && err != ErrExists && err != ErrNotReady {
+		t.mu.Lock()
+		if t.err == nil {
+			t.err = err
+		}
+		t.mu.Unlock()
+	}
+	return err
+}
+
+func (t *txDB) failed() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// savepoint passes all access on to tx and records how to revert each write.
+// Bucket sequences are not reverted; rolled back writes only skip some IDs.
+type savepoint struct {
+	tx   kv.Tx
+	undo []func(kv.Tx) error
+}
+
+// Revert all writes in reverse order.
+func (s *savepoint) rollback() error {
+	for i := len(s.undo) - 1; i >= 0; i-- {
+		if err := s.undo[i](s.tx); err != nil {
+			return err
+		}
+	}
+	s.undo = nil
+	return nil
+}
+
+func (s *savepoint) Bucket(name []byte) kv.Bucket {
+	b := s.tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return savepointBucket{b, s, string(name)}
+}
+
+func (s *savepoint) CreateBucketIfNotExists(name []byte) (kv.Bucket, error) {
+	exists := s.tx.Bucket(name) != nil
+	if _, err := s.tx.CreateBucketIfNotExists(name); err != nil {
+		return nil, err
+	}
+	if !exists {
+		n := string(name)
+		s.undo = append(s.undo, func(tx kv.Tx) error {
+			return tx.DeleteBucket([]byte(n))
+		})
+	}
+	return s.Bucket(name), nil
+}
+
+func (s *savepoint) DeleteBucket(name []byte) error {
+	var entries [][2][]byte
+	if b := s.tx.Bucket(name); b != nil {
+		err := b.ForEach(func(k, v []byte) error {
+			entries = append(entries, [2][]byte{append([]byte{}, k...), append([]byte{}, v...)})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := s.tx.DeleteBucket(name); err != nil {
+		return err
+	}
+	n := string(name)
+	s.undo = append(s.undo, func(tx kv.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(n))
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := b.Put(e[0], e[1]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return nil
+}
+
+func (s *savepoint) ForEach(fn func(name []byte, b kv.Bucket) error) error {
+	return s.tx.ForEach(func(name []byte, _ kv.Bucket) error {
+		return fn(name, s.Bucket(name))
+	})
+}
+
+func (s *savepoint) Size() int64 {
+	return s.tx.Size()
+}
+
+// savepointBucket records the previous value of each key it writes.
+type savepointBucket struct {
+	kv.Bucket
+	sp   *savepoint
+	name string
+}
+
+// Remember to restore the current value of key.
+func (b savepointBucket) record(key []byte) {
+	k := append([]byte{}, key...)
+	var prev []byte
+	if v := b.Get(key); v != nil {
+		prev = append([]byte{}, v...)
+	}
+	name := b.name
+	b.sp.undo = append(b.sp.undo, func(tx kv.Tx) error {
+		bucket := tx.Bucket([]byte(name))
+		if bucket == nil {
+			return nil
+		}
+		if prev == nil {
+			return bucket.Delete(k)
+		}
+		return bucket.Put(k, prev)
+	})
+}
+
+func (b savepointBucket) Put(key, value []byte) error {
+	b.record(key)
+	return b.Bucket.Put(key, value)
+}
+
+func (b savepointBucket) Delete(key []byte) error {
+	if b.Get(key) == nil {
+		return nil
+	}
+	b.record(key)
+	return b.Bucket.Delete(key)
+}
+
+// Close closes the underlying database, same as for any other Store.
+func (t *txDB) Close() error {
+	return t.db.Close()
+}
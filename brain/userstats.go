@@ -5,8 +5,8 @@ import (
 	"fmt"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 // UserStats returns the Stats object for a user.
@@ -14,7 +14,7 @@ import (
 // Otherwise returns ErrNotReady.
 func (store Store) UserStats(id int64) (Stats, error) {
 	var stats Stats
-	err := store.db.Update(func(tx *bolt.Tx) error {
+	err := store.db.Update(func(tx kv.Tx) error {
 		b := tx.Bucket(bucket.Stattimes)
 		prefix := itob(id)
 		now := time.Now()
@@ -33,16 +33,17 @@ func (store Store) UserStats(id int64) (Stats, error) {
 			return ErrNotReady
 		}
 
-		score, rank, err := scoreAndRank(tx, prefix)
-		if err != nil {
-			return err
-		}
+		score := getScoretotal(tx, prefix)
+		rank, percentile, monthRank := getRank(tx, prefix, score)
 
 		stats = Stats{
-			Added:   countAdds(tx, prefix, now),
-			Studied: countStudies(tx, prefix, now),
-			Score:   score,
-			Rank:    rank,
+			Added:      countAdds(tx, prefix, now),
+			Studied:    countStudies(tx, prefix, now),
+			Score:      score,
+			Rank:       rank,
+			Percentile: percentile,
+			MonthRank:  monthRank,
+			Streak:     getStreak(tx, prefix, now),
 		}
 
 		return b.Put(prefix, itob(now.Unix()))
@@ -57,7 +58,7 @@ func (store Store) UserStats(id int64) (Stats, error) {
 	return stats, nil
 }
 
-func countAdds(tx *bolt.Tx, prefix []byte, now time.Time) int {
+func countAdds(tx kv.Tx, prefix []byte, now time.Time) int {
 	count := 0
 	limit := now.Add(-statInterval).Unix()
 	c := tx.Bucket(bucket.PhraseAddTimes).Cursor()
@@ -71,13 +72,13 @@ func countAdds(tx *bolt.Tx, prefix []byte, now time.Time) int {
 	return count
 }
 
-func countStudies(tx *bolt.Tx, prefix []byte, now time.Time) int {
+func countStudies(tx kv.Tx, prefix []byte, now time.Time) int {
 	count := 0
 	limit := now.Add(-statInterval).Unix()
 	c := tx.Bucket(bucket.Studies).Cursor()
 
 	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
-		if btoi(k[8:]) > limit {
+		if btoi(k[8:16]) > limit {
 			count++
 		}
 	}
@@ -85,21 +86,9 @@ func countStudies(tx *bolt.Tx, prefix []byte, now time.Time) int {
 	return count
 }
 
-func scoreAndRank(tx *bolt.Tx, prefix []byte) (int, int, error) {
-	b := tx.Bucket(bucket.Scoretotals)
-
-	score := 0
-	if v := b.Get(prefix); v != nil {
-		score = int(btoi(v))
+func getScoretotal(tx kv.Tx, prefix []byte) int {
+	if v := tx.Bucket(bucket.Scoretotals).Get(prefix); v != nil {
+		return int(btoi(v))
 	}
-
-	rank := 1
-	err := b.ForEach(func(k, v []byte) error {
-		if !bytes.Equal(k, prefix) && int(btoi(v)) > score {
-			rank++
-		}
-		return nil
-	})
-
-	return score, rank, err
+	return 0
 }
@@ -3,14 +3,18 @@ package brain
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
-	bolt "github.com/coreos/bbolt"
 	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
+var errNoBackup = errors.New("database does not support backups")
+
 func itob(v int64) []byte {
 	b := make([]byte, 8)
 	binary.BigEndian.PutUint64(b, uint64(v))
@@ -22,15 +26,22 @@ func btoi(b []byte) int64 {
 }
 
 // BackupTo streams backup as an HTTP response.
+// Only databases that can be written as a file, like Bolt, support backups.
 func (store Store) BackupTo(w http.ResponseWriter) {
-	err := store.db.View(func(tx *bolt.Tx) error {
+	err := store.db.View(func(tx kv.Tx) error {
+		wt, ok := tx.(io.WriterTo)
+		if !ok {
+			return errNoBackup
+		}
 		w.Header().Set("Content-Type", "application/octet-stream")
 		w.Header().Set("Content-Disposition", `attachment; filename="my.db"`)
 		w.Header().Set("Content-Length", strconv.Itoa(int(tx.Size())))
-		_, err := tx.WriteTo(w)
+		_, err := wt.WriteTo(w)
 		return err
 	})
-	if err != nil {
+	if err == errNoBackup {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
@@ -39,18 +50,30 @@ func (store Store) BackupTo(w http.ResponseWriter) {
 // Key is nil if non could be found.
 // Also returns the total number of due studies and a duration until the next phrase is due.
 // The duration is only useful if total is 0 otherwise the duration is a negative time.
-func findCurrentStudy(tx *bolt.Tx, prefix []byte, now time.Time) ([]byte, int, time.Duration) {
-	c := tx.Bucket(bucket.Studytimes).Cursor()
+// If the user chose to study a tag, only phrases with that tag are considered.
+// Suspended phrases are skipped.
+func findCurrentStudy(tx kv.Tx, prefix []byte, now time.Time) ([]byte, int, time.Duration) {
+	c := tx.Bucket(bucket.Dues).Cursor()
+	tag := tx.Bucket(bucket.StudyTags).Get(prefix)
 	uNow := now.Unix()
 	total := 0
 	var keyTime int64
 	var key []byte
 
-	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-		timestamp := btoi(v)
-		if timestamp < keyTime || keyTime == 0 {
+	// The due index is ordered by time, the first card found is studied next.
+	// Stop once all due cards are counted.
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		timestamp := btoi(k[8:16])
+		if key != nil && timestamp > uNow {
+			break
+		}
+		card := dueCard(k)
+		if tag != nil && !hasTag(tx, card, tag) || isSuspended(tx, card) {
+			continue
+		}
+		if key == nil {
 			keyTime = timestamp
-			key = k
+			key = card
 		}
 		if timestamp <= uNow {
 			total++
@@ -62,7 +85,7 @@ func findCurrentStudy(tx *bolt.Tx, prefix []byte, now time.Time) ([]byte, int, t
 
 // Add a count to a bucket value.
 // Limits to >= 0.
-func addCountToBucket(b *bolt.Bucket, key []byte, count int) error {
+func addCountToBucket(b kv.Bucket, key []byte, count int) error {
 	if v := b.Get(key); v != nil {
 		count += int(btoi(v))
 	}
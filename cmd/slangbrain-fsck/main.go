@@ -0,0 +1,85 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+const cliUsage = `Slangbrain Fsck
+
+Usage: %s [-repair] path_to_db_file
+
+Slangbrain Fsck cross-checks all buckets of a Slangbrain DB file and reports inconsistencies per user to stdout.
+Pass -repair to fix all problems found in a single transaction.
+Without -repair the file is not changed, so it must not need any migrations.
+Exits with status 1 if problems remain.
+
+Make sure Slangbrain itself is stopped or run it with a DB backup as file.
+`
+
+func main() {
+	errs := log.New(os.Stderr, "", 0)
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, cliUsage, os.Args[0])
+		flag.PrintDefaults()
+	}
+	repair := flag.Bool("repair", false, "Fix all problems found.")
+	flag.Parse()
+
+	db := flag.Arg(0)
+	if db == "" || db == "help" {
+		flag.Usage()
+		os.Exit(1)
+	}
+	if _, err := os.Stat(db); os.IsNotExist(err) {
+		errs.Fatalf("no file found at '%s'", db)
+	}
+
+	// Setup database, only migrate when repairing
+	var options []func(*brain.Store)
+	if !*repair {
+		options = append(options, brain.NoMigrate)
+	}
+	store, err := brain.New(db, options...)
+	if err != nil {
+		errs.Fatalln("failed to create store:", err)
+	}
+
+	problems, err := store.Check(*repair)
+	if err != nil {
+		errs.Println(err)
+	}
+	if err := store.Close(); err != nil {
+		errs.Println("failed to close store:", err)
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+
+	// Group by user
+	for i, p := range problems {
+		if i == 0 || p.ID != problems[i-1].ID {
+			if p.ID == 0 {
+				fmt.Println("all users:")
+			} else {
+				fmt.Printf("user %d:\n", p.ID)
+			}
+		}
+		fmt.Printf("  %s\n", p)
+	}
+
+	switch {
+	case len(problems) == 0:
+		fmt.Println("no problems found")
+	case *repair:
+		fmt.Printf("repaired %d problems\n", len(problems))
+	default:
+		fmt.Printf("found %d problems, run with -repair to fix them\n", len(problems))
+		os.Exit(1)
+	}
+}
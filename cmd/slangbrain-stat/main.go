@@ -14,6 +14,7 @@ Usage: %s [path_to_db_file]
 Slangbrain Stat outputs text-based statistics for a passed Slangbrain DB file to stdout.
 It is best run with a DB backup as file to not put more load on Slangbrain itself
 and it also ensures that backups are working properly.
+The file is not changed, so it must not need any migrations.
 `
 
 func main() {
@@ -32,7 +33,7 @@ func main() {
 	}
 
 	// Setup database
-	store, err := brain.New(db)
+	store, err := brain.New(db, brain.NoMigrate)
 	if err != nil {
 		errs.Fatalln("failed to create store:", err)
 	}
@@ -49,7 +49,7 @@ func TestAdd(t *testing.T) {
 		},
 		{
 			name:   "menu",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"What would you like to do next?\nPlease use the buttons below.","quick_replies":[{"content_type":"text","title":"🏫 study","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ phrases","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ help","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ done","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"What would you like to do next?\nPlease use the buttons below.","quick_replies":[{"content_type":"text","title":"🏫 study","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ phrases","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ help","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ done","payload":"PAYLOAD_IDLE"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_STARTSTUDY"),
 		},
 		{
@@ -59,7 +59,7 @@ func TestAdd(t *testing.T) {
 		},
 		{
 			name:   "refuse to subscribe",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Sure, you won't receive any notifications.\n\nWhat would you like to do next?\nPlease use the buttons below.","quick_replies":[{"content_type":"text","title":"🏫 study","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ phrases","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ help","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ done","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sure, you won't receive any notifications.\n\nWhat would you like to do next?\nPlease use the buttons below.","quick_replies":[{"content_type":"text","title":"🏫 study","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ phrases","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ help","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ done","payload":"PAYLOAD_IDLE"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_IDLE"),
 		},
 		{
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestFuzzyAnswer(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "café", "coffee", yesterday))
+	fatal(t, store.AddPhrase(123, "gracias", "thanks", yesterday))
+	fatal(t, store.AddPhrase(123, "sí", "yes", yesterday))
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now()))
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "missing diacritic",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Almost correct! Here is what's different:\n\ncaf~e~*é*"}}`,
+		},
+		{
+			name:   "review 2",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"2. Do you know how to say this?\n\nthanks\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatMessage, "2", "Gracas"),
+		},
+		{
+			name:   "typo",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Almost correct! Here is what's different:\n\ngrac*i*as"}}`,
+		},
+		{
+			name:   "review 3",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nyes\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatMessage, "3", "so"),
+		},
+		{
+			name:   "short phrases need to be exact",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sorry, the right version is:\n\nsí"}}`,
+		},
+		{
+			name:   "done",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nStudied: 3\nCorrect: 66%\nLearned: 0\nHardest: sí\nStreak: 1 day\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatMessage, "1", "cafe"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+}
+
+func TestAlternatives(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	_, err := store.Import(123, []brain.Phrase{
+		{Phrase: "hola", Explanation: "hello", Alternatives: []string{"buenas", " ", "hola"}},
+		{Phrase: "gracias", Explanation: "thanks", Alternatives: []string{"muchas gracias"}},
+	})
+	fatal(t, err)
+	fatal(t, store.AddPhrase(123, "adiós", "bye", time.Now()))
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "alternative",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Correct!"}}`,
+		},
+		{
+			name:   "review 2",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nthanks\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatMessage, "2", "de nada"),
+		},
+		{
+			name:   "wrong shows all answers",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sorry, the right version is:\n\ngracias / muchas gracias"}}`,
+		},
+		{
+			name:   "done",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nStudied: 2\nCorrect: 50%\nLearned: 1\nHardest: gracias\nStreak: 1 day\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatMessage, "1", "Buenas!"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+}
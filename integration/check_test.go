@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+func TestCheck(t *testing.T) {
+	db := kv.NewMemory()
+	store, err := brain.Open(db)
+	fatal(t, err)
+	defer func() {
+		fatal(t, store.Close())
+	}()
+
+	// Regular usage leaves no problems
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "hola", "hello", yesterday))
+	fatal(t, store.AddPhrase(123, "adiós", "bye", yesterday))
+	fatal(t, store.AddPhrase(123, "{{c1::sí}} señor", "yes sir", yesterday))
+	fatal(t, store.AddPhrase(124, "danke", "thanks", yesterday))
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	fatal(t, store.SetDirection(123, 2, brain.DirectionBoth))
+	fatal(t, store.SetTags(123, 2, []string{"travel"}))
+	fatal(t, store.SuspendPhrase(123, 3, true))
+	fatal(t, store.DeletePhrase(123, 1))
+	_, err = store.GenerateToken(123)
+	fatal(t, err)
+	expectProblems := func(name string, expected map[int64][]string) {
+		problems, err := store.Check(false)
+		fatal(t, err)
+		found := map[int64][]string{}
+		for _, p := range problems {
+			found[p.ID] = append(found[p.ID], p.Bucket)
+		}
+		if len(found) != len(expected) {
+			t.Errorf("%s: expected problems %v; got %v", name, expected, problems)
+			return
+		}
+		for id, buckets := range expected {
+			if len(found[id]) != len(buckets) {
+				t.Errorf("%s: expected problems %v for %d; got %v", name, buckets, id, problems)
+				continue
+			}
+			for i, b := range buckets {
+				if found[id][i] != b {
+					t.Errorf("%s: expected problems %v for %d; got %v", name, buckets, id, problems)
+				}
+			}
+		}
+	}
+	expectProblems("regular usage", nil)
+
+	// Break the database
+	phrases, err := store.GetAllPhrases(124)
+	fatal(t, err)
+	itob := func(v int64) []byte {
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, uint64(v))
+		return b
+	}
+	phraseKey := func(id, seq int64) []byte {
+		return append(itob(id), itob(seq)...)
+	}
+	fatal(t, db.Update(func(tx kv.Tx) error {
+		for _, put := range []struct {
+			bucket []byte
+			key    []byte
+			value  []byte
+		}{
+			{bucket.Studytimes, phraseKey(123, 9), itob(0)},
+			{bucket.Zeroscores, itob(124), itob(5)},
+			{bucket.Scoretotals, itob(124), itob(7)},
+			{bucket.AuthTokens, []byte("dangling"), itob(125)},
+		} {
+			if err := tx.Bucket(put.bucket).Put(put.key, put.value); err != nil {
+				return err
+			}
+		}
+		return tx.Bucket(bucket.PhraseAddTimes).Delete(phraseKey(124, phrases[0].ID))
+	}))
+	// The scoretotal was added without ranking the user
+	// and the removed add time is still in the added index
+	expectProblems("broken", map[int64][]string{
+		0:   {"ranks"},
+		123: {"studytimes"},
+		124: {"phraseaddtimes", "addedphrases", "scoretotals", "zeroscores"},
+		125: {"authtokens"},
+	})
+
+	problems, err := store.Check(true)
+	fatal(t, err)
+	if len(problems) != 7 {
+		t.Errorf("expected 7 problems to be repaired; got %v", problems)
+	}
+	expectProblems("repaired", nil)
+
+	fatal(t, db.Update(func(tx kv.Tx) error {
+		// Both users are still counted, but with the highest possible score
+		if err := tx.DeleteBucket(bucket.Ranks); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(bucket.Ranks); err != nil {
+			return err
+		}
+		if err := tx.Bucket(bucket.Ranks).Put(append(itob(0), itob(1<<32)...), itob(2)); err != nil {
+			return err
+		}
+		// Leftovers of a user that doesn't exist anymore
+		for _, b := range [][]byte{bucket.Settings, bucket.Undos, bucket.Hints, bucket.Streaks, bucket.Schedulers} {
+			if err := tx.Bucket(b).Put(itob(126), itob(1)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	expectProblems("wrong ranks and orphans", map[int64][]string{
+		0:   {"ranks"},
+		126: {"settings", "undos", "hints", "streaks", "schedulers"},
+	})
+
+	problems, err = store.Check(true)
+	fatal(t, err)
+	if len(problems) != 6 {
+		t.Errorf("expected 6 problems to be repaired; got %v", problems)
+	}
+	expectProblems("repaired again", nil)
+	rank, err := store.GetRank(123)
+	fatal(t, err)
+	if rank.Rank != 1 || rank.Percentile != 100 {
+		t.Errorf("expected ranks to be rebuilt; got %#v", rank)
+	}
+}
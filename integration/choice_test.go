@@ -0,0 +1,142 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/payload"
+)
+
+func TestMultipleChoice(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	s := brain.DefaultSettings
+	s.MultipleChoice = true
+	fatal(t, store.SetSettings(123, s))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	for i, p := range [][2]string{
+		{"hola", "hello"},
+		{"adiós", "bye"},
+		{"gracias", "thanks"},
+		{"perro", "dog"},
+		{"un gato muy grande", "cat"},
+	} {
+		fatal(t, store.AddPhrase(123, p[0], p[1], yesterday.Add(time.Duration(i)*time.Minute)))
+	}
+	fatal(t, store.SetTags(123, 1, []string{"greetings"}))
+	fatal(t, store.SetTags(123, 2, []string{"greetings"}))
+
+	// Distractors share a tag or have a similar length
+	study, options, err := store.GetChoices(123)
+	fatal(t, err)
+	if expected := []string{"adiós", "gracias", "hola", "perro"}; study.Phrase != "hola" || !reflect.DeepEqual(options, expected) {
+		t.Errorf("expected options %v for hola; got %v for %s", expected, options, study.Phrase)
+	}
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "question 1",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"5. Do you know how to say this?\n\nhello\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"adiós","payload":"PAYLOAD_CHOICE:1:0"},{"content_type":"text","title":"gracias","payload":"PAYLOAD_CHOICE:1:1"},{"content_type":"text","title":"hola","payload":"PAYLOAD_CHOICE:1:2"},{"content_type":"text","title":"perro","payload":"PAYLOAD_CHOICE:1:3"}]}}`,
+			send:   fmt.Sprintf(formatPayload, payload.EncodeChoice(1, 2)),
+		},
+		{
+			name:   "correct",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Correct!"}}`,
+		},
+		{
+			name:   "question 2",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"4. Do you know how to say this?\n\nbye\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"adiós","payload":"PAYLOAD_CHOICE:2:0"},{"content_type":"text","title":"gracias","payload":"PAYLOAD_CHOICE:2:1"},{"content_type":"text","title":"hola","payload":"PAYLOAD_CHOICE:2:2"},{"content_type":"text","title":"perro","payload":"PAYLOAD_CHOICE:2:3"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatPayload, payload.EncodeChoice(2, 2)),
+		},
+		{
+			name:   "wrong",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sorry, the right version is:\n\nadiós"}}`,
+		},
+		{
+			name:   "question 3",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"3. Do you know how to say this?\n\nthanks\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"adiós","payload":"PAYLOAD_CHOICE:3:0"},{"content_type":"text","title":"gracias","payload":"PAYLOAD_CHOICE:3:1"},{"content_type":"text","title":"hola","payload":"PAYLOAD_CHOICE:3:2"},{"content_type":"text","title":"perro","payload":"PAYLOAD_CHOICE:3:3"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatPayload, payload.EncodeChoice(1, 2)),
+		},
+		{
+			name:   "option of old study",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"3. Do you know how to say this?\n\nthanks\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"adiós","payload":"PAYLOAD_CHOICE:3:0"},{"content_type":"text","title":"gracias","payload":"PAYLOAD_CHOICE:3:1"},{"content_type":"text","title":"hola","payload":"PAYLOAD_CHOICE:3:2"},{"content_type":"text","title":"perro","payload":"PAYLOAD_CHOICE:3:3"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatPayload, payload.Continue))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		if p.Phrase == "hola" && p.Score != 1 {
+			t.Errorf("expected correct choice to be graded good; got %#v", p)
+		}
+		if p.Phrase == "adiós" && p.Score != 0 {
+			t.Errorf("expected wrong choice to be graded again; got %#v", p)
+		}
+	}
+}
+
+func TestChoicePayload(t *testing.T) {
+	// Reverse cards have the highest bit set
+	card := int64(-1<<63 | 42)
+	p := payload.EncodeChoice(card, 3)
+	c, o, err := payload.DecodeChoice(p)
+	fatal(t, err)
+	if c != card || o != 3 {
+		t.Errorf("expected card and option to be decoded; got %d and %d from %s", c, o, p)
+	}
+	if len(p) > 1000 {
+		t.Errorf("expected payload to fit into 1000 chars; got %d", len(p))
+	}
+	for _, p := range []string{payload.Undo, payload.Choice + "o=hola&q=hello"} {
+		if _, _, err := payload.DecodeChoice(p); err == nil {
+			t.Errorf("expected error for payload %s", p)
+		}
+	}
+}
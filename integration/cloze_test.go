@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestCloze(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	cloze := "Ich {{c1::habe}} {{c2::Hunger}}"
+	_, err := store.Import(123, []brain.Phrase{{Phrase: cloze, Explanation: "I am hungry"}})
+	fatal(t, err)
+	// Phrase is due in an hour to know when studies are done
+	fatal(t, store.AddPhrase(123, "danke", "thanks", time.Now()))
+
+	// Each cloze is a separate card
+	study, err := store.GetStudy(123)
+	fatal(t, err)
+	// The cloze number is stored in the highest byte of the card
+	expected := brain.Study{Card: 1<<56 | 1, Phrase: "habe", Explanation: "Ich [...] Hunger", Cloze: true, Total: 2}
+	if fmt.Sprint(study) != fmt.Sprint(expected) {
+		t.Errorf("expected study %#v; got %#v", expected, study)
+	}
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "correct",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Correct!"}}`,
+		},
+		{
+			name:   "second cloze",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know what is missing?\n\nIch habe [...]\n\nUse the buttons or type the missing part.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatMessage, "2", "Ich habe Hunger"),
+		},
+		{
+			name:   "graded against the hidden word only",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sorry, the right version is:\n\nHunger"}}`,
+		},
+		{
+			name:   "done",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nStudied: 2\nCorrect: 50%\nLearned: 1\nHardest: Ich habe Hunger\nStreak: 1 day\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatMessage, "1", "habe"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+
+	// Markup is kept
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	found := false
+	for _, p := range phrases {
+		found = found || p.Phrase == cloze
+	}
+	if !found {
+		t.Errorf("expected phrase %s; got %#v", cloze, phrases)
+	}
+}
+
+func TestUpdateCloze(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	_, err := store.Import(123, []brain.Phrase{{Phrase: "{{c1::uno}}, {{c2::dos}}", Explanation: "one, two"}})
+	fatal(t, err)
+
+	for _, c := range []struct {
+		phrase string
+		total  int
+	}{
+		{"{{c1::uno}}, {{c2::dos}}", 2},
+		// Removed clozes are not studied anymore
+		{"{{c1::uno}}, dos", 1},
+		// Added clozes are queued as new phrases
+		{"{{c1::uno}}, {{c2::dos}}, {{c3::tres}}", 1},
+		// Plain phrases are studied as usual
+		{"uno, dos, tres", 0},
+	} {
+		phrases, err := store.GetAllPhrases(123)
+		fatal(t, err)
+		fatal(t, store.UpdatePhrase(123, int(phrases[0].ID), c.phrase, "one, two"))
+		study, err := store.GetStudy(123)
+		fatal(t, err)
+		if study.Total != c.total {
+			t.Errorf("expected %d studies for %s; got %#v", c.total, c.phrase, study)
+		}
+	}
+}
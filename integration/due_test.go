@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestDueOrder(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "uno", "one", yesterday.Add(-2*time.Hour)))
+	fatal(t, store.AddPhrase(123, "dos", "two", yesterday.Add(-time.Hour)))
+	fatal(t, store.AddPhrase(123, "tres", "three", yesterday))
+
+	expectStudy := func(name, phrase string, total int) {
+		study, err := store.GetStudy(123)
+		fatal(t, err)
+		if study.Phrase != phrase || study.Total != total {
+			t.Errorf("%s: expected to study %s of %d; got %#v", name, phrase, total, study)
+		}
+	}
+
+	expectStudy("oldest first", "uno", 3)
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		if p.Phrase == "uno" {
+			fatal(t, store.DeletePhrase(123, int(p.ID)))
+		}
+	}
+	expectStudy("deleted", "dos", 2)
+
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	expectStudy("scored", "tres", 1)
+
+	fatal(t, store.UndoLastStudy(123))
+	expectStudy("undone", "dos", 2)
+
+	_, count, err := store.GetNotifyTime(123, 0)
+	fatal(t, err)
+	if count != 2 {
+		t.Errorf("expected 2 due studies; got %d", count)
+	}
+}
@@ -0,0 +1,48 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestExplanationIndex(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	_, err := store.Import(123, []brain.Phrase{{Phrase: "hola", Explanation: "hello"}})
+	fatal(t, err)
+
+	// Small differences are still duplicates
+	p, err := store.FindExplanation(123, " Hello ")
+	fatal(t, err)
+	if p.Phrase != "hola" {
+		t.Errorf("expected to find hola; got %#v", p)
+	}
+	count, err := store.QueueImport(123, []brain.Phrase{
+		{Phrase: "hallo", Explanation: "HELLO"},
+		{Phrase: "danke", Explanation: "thanks"},
+	})
+	fatal(t, err)
+	if count != 1 {
+		t.Errorf("expected only one phrase to be queued; got %d", count)
+	}
+
+	// Other users don't share the index
+	if _, err := store.FindExplanation(124, "hello"); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for other user; got %v", err)
+	}
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	fatal(t, store.UpdatePhrase(123, int(phrases[0].ID), "hola", "hi"))
+	if _, err := store.FindExplanation(123, "hello"); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for updated explanation; got %v", err)
+	}
+	_, err = store.FindExplanation(123, "hi")
+	fatal(t, err)
+
+	fatal(t, store.DeletePhrase(123, int(phrases[0].ID)))
+	if _, err := store.FindExplanation(123, "hi"); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for deleted phrase; got %v", err)
+	}
+}
@@ -50,12 +50,12 @@ func TestFeedback(t *testing.T) {
 		},
 		{
 			name:   "menu",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Was willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Was willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 Tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SHOWHELP"),
 		},
 		{
 			name:   "help",
-			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"},{"content_type":"text","title":"Vorschau","payload":"PAYLOAD_FORECAST"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_FEEDBACK"),
 		},
 		{
@@ -69,7 +69,7 @@ func TestFeedback(t *testing.T) {
 		},
 		{
 			name:   "menu 2",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Was willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Was willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 Tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
 		},
 	}
 
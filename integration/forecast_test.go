@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestForecast(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	s := brain.DefaultSettings
+	s.NewPhrases = 1
+	fatal(t, store.SetSettings(123, s))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase2", "explanation2", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase3", "explanation3", yesterday))
+
+	forecast, err := store.Forecast(123, 3)
+	fatal(t, err)
+	// The scheduled phrase is overdue, the others are introduced once it has been learned
+	for i, expected := range []brain.ForecastDay{{Due: 1, New: 1}, {New: 1}, {}} {
+		if forecast[i].Due != expected.Due || forecast[i].New != expected.New {
+			t.Errorf("expected day %d to be %#v; got %#v", i, expected, forecast[i])
+		}
+	}
+
+	if _, err := store.Forecast(123, 0); err == nil {
+		t.Error("expected forecast without days to fail")
+	}
+
+	// Read forecast via API
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(api.Forecast(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "?token=" + apiToken)
+	fatal(t, err)
+	var data struct {
+		Data []brain.ForecastDay `json:"data"`
+	}
+	fatal(t, json.NewDecoder(res.Body).Decode(&data))
+	if len(data.Data) != brain.ForecastDays {
+		t.Errorf("expected %d days; got %#v", brain.ForecastDays, data.Data)
+	}
+
+	res, err = http.Get(ts.URL + "?token=" + apiToken + "&days=1000")
+	fatal(t, err)
+	if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected too many days to fail; got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestGoal(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now().Add(-48*time.Hour)))
+	fatal(t, store.AddPhrase(123, "adiós", "bye", time.Now()))
+
+	// No nudges without goals
+	if _, err := store.GetGoalNudgeTime(123, 2); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound; got %v", err)
+	}
+
+	settings := brain.DefaultSettings
+	settings.DailyStudies = 10
+	settings.DailyAdds = 1
+	fatal(t, store.SetSettings(123, settings))
+
+	_, err := store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+
+	p, err := store.GetProgress(123, 2)
+	fatal(t, err)
+	expected := brain.Progress{Studied: 1, StudyGoal: 10, Added: 1, AddGoal: 1}
+	if p != expected {
+		t.Errorf("expected progress %#v; got %#v", expected, p)
+	}
+	if p.Met() {
+		t.Errorf("expected goal not to be met: %#v", p)
+	}
+
+	d, err := store.GetGoalNudgeTime(123, 2)
+	fatal(t, err)
+	if d <= 0 || d > 24*time.Hour {
+		t.Errorf("expected nudge within the next day; got %s", d)
+	}
+
+	// Nudges wait for the quiet hours to end on the same day
+	local := func(d time.Duration) int {
+		return time.Now().Add(d).UTC().Add(2 * time.Hour).Hour()
+	}
+	settings.QuietStart = 18
+	settings.QuietEnd = 20
+	fatal(t, store.SetSettings(123, settings))
+	d, err = store.GetGoalNudgeTime(123, 2)
+	fatal(t, err)
+	if h := local(d); h != 20 {
+		t.Errorf("expected nudge after quiet hours at 20h; got %dh", h)
+	}
+	settings.QuietEnd = 7
+	fatal(t, store.SetSettings(123, settings))
+	if _, err := store.GetGoalNudgeTime(123, 2); err != brain.ErrNotFound {
+		t.Errorf("expected no nudge in quiet hours lasting until the next day; got %v", err)
+	}
+
+	// Nudges are skipped while the last notification is unread
+	if unread, err := store.HasUnreadNotify(123); err != nil || unread {
+		t.Errorf("expected no unread notification; got %t, %v", unread, err)
+	}
+	now := time.Now()
+	fatal(t, store.TrackNotify(123, now))
+	if unread, err := store.HasUnreadNotify(123); err != nil || !unread {
+		t.Errorf("expected unread notification; got %t, %v", unread, err)
+	}
+	fatal(t, store.SetRead(123, now.Add(time.Second)))
+	if unread, err := store.HasUnreadNotify(123); err != nil || unread {
+		t.Errorf("expected notification to be read; got %t, %v", unread, err)
+	}
+}
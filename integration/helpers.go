@@ -4,6 +4,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 	"testing"
 
 	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/brain/kv"
 )
 
 const (
@@ -74,13 +76,23 @@ type testCase struct {
 	send     string
 }
 
-func fatal(t *testing.T, err error) {
+func fatal(t testing.TB, err error) {
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
-func initDB(t *testing.T) (brain.Store, func()) {
+// Run tests with a different storage using -args -backend=memory.
+var backend = flag.String("backend", "bolt", "Storage backend used by tests: bolt or memory.")
+
+func initDB(t testing.TB) (brain.Store, func()) {
+	if *backend == "memory" {
+		store, err := brain.Open(kv.NewMemory())
+		fatal(t, err)
+		return store, func() {
+			fatal(t, store.Close())
+		}
+	}
 	f, err := ioutil.TempFile("", "slangbrain-test")
 	fatal(t, err)
 	fatal(t, f.Close())
@@ -119,7 +131,7 @@ func noMatchNorDefault(val, expect, defaultVal string) bool {
 }
 
 // Send a message to Slangbrain.
-func send(t *testing.T, handler http.Handler, message string) {
+func send(t testing.TB, handler http.Handler, message string) {
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", appURL, strings.NewReader(message))
 
@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestHint(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "Hola amigo", "hi friend", yesterday))
+	fatal(t, store.AddPhrase(123, "sí", "yes", yesterday.Add(time.Minute)))
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "word count",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Hint:\n\n____ _____","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_HINT"),
+		},
+		{
+			name:   "first letters",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Hint:\n\nH___ a____","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREEASY"),
+		},
+		{
+			name:   "next study",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nyes\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_HINT"),
+		},
+		{
+			name:   "hint 1",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Hint:\n\n__","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_HINT"),
+		},
+		{
+			name:   "hint 2",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Hint:\n\ns_","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_HINT"),
+		},
+		{
+			name:   "revealed",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"sí","quick_replies":[{"content_type":"text","title":"👎 again","payload":"PAYLOAD_SCOREAGAIN"},{"content_type":"text","title":"🤔 hard","payload":"PAYLOAD_SCOREHARD"},{"content_type":"text","title":"👌 good","payload":"PAYLOAD_SCOREGOOD"},{"content_type":"text","title":"💯 easy","payload":"PAYLOAD_SCOREEASY"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatPayload, "PAYLOAD_HINT"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		// Easy with two hints is only worth a score of 0
+		if p.Phrase == "Hola amigo" && p.Score != 0 {
+			t.Errorf("expected hints to reduce the score; got %#v", p)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestHistory(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase2", "explanation2", yesterday))
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	seq := int(phrases[1].ID)
+	// Only study phrase1
+	fatal(t, store.SuspendPhrase(123, int(phrases[0].ID), true))
+
+	reviews, stats, err := store.GetHistory(123, seq)
+	fatal(t, err)
+	if len(reviews) != 0 || stats.Reviews != 0 {
+		t.Errorf("expected no reviews; got %#v, %#v", reviews, stats)
+	}
+
+	// Studies are stored by second
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	time.Sleep(time.Second)
+	_, err = store.ScoreStudy(123, brain.GradeAgain)
+	fatal(t, err)
+	time.Sleep(time.Second)
+	_, err = store.ScoreStudy(123, brain.GradeEasy)
+	fatal(t, err)
+
+	reviews, stats, err = store.GetHistory(123, seq)
+	fatal(t, err)
+	if len(reviews) != 3 || reviews[0].Grade != brain.GradeGood || reviews[0].Score != 1 || !reviews[1].Failed || reviews[1].Score != 0 {
+		t.Errorf("expected three reviews of phrase1; got %#v", reviews)
+	}
+	if stats.Reviews != 3 || stats.Lapses != 1 || stats.SuccessRate < 0.66 || stats.SuccessRate > 0.67 || stats.AverageInterval <= 0 {
+		t.Errorf("unexpected stats %#v", stats)
+	}
+
+	// Undo removes the last review
+	fatal(t, store.UndoLastStudy(123))
+	reviews, _, err = store.GetHistory(123, seq)
+	fatal(t, err)
+	if len(reviews) != 2 {
+		t.Errorf("expected undone review to be removed; got %#v", reviews)
+	}
+
+	if problems, err := store.Check(false); err != nil || len(problems) != 0 {
+		t.Errorf("expected history index to be consistent; got %v, %v", problems, err)
+	}
+
+	// Read history via API
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(http.StripPrefix("/", api.Phrases(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile))))
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/" + strconv.Itoa(seq) + "/history?token=" + apiToken)
+	fatal(t, err)
+	var data struct {
+		Data struct {
+			Reviews []brain.Review    `json:"reviews"`
+			Stats   brain.PhraseStats `json:"stats"`
+		} `json:"data"`
+	}
+	fatal(t, json.NewDecoder(res.Body).Decode(&data))
+	fatal(t, res.Body.Close())
+	if len(data.Data.Reviews) != 2 || data.Data.Stats.Lapses != 1 {
+		t.Errorf("expected history from API; got %#v", data.Data)
+	}
+
+	fatal(t, store.DeletePhrase(123, seq))
+	if _, _, err := store.GetHistory(123, seq); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for deleted phrase; got %v", err)
+	}
+	res, err = http.Get(ts.URL + "/" + strconv.Itoa(seq) + "/history?token=" + apiToken)
+	fatal(t, err)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected deleted phrase to be not found; got %d", res.StatusCode)
+	}
+}
+
+func TestHistorySameSecond(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", time.Now().Add(-24*time.Hour)))
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	_, err = store.ScoreStudy(123, brain.GradeAgain)
+	fatal(t, err)
+
+	reviews, stats, err := store.GetHistory(123, int(phrases[0].ID))
+	fatal(t, err)
+	if len(reviews) != 2 || reviews[0].Grade != brain.GradeGood || reviews[0].Score != 1 || reviews[1].Grade != brain.GradeAgain || !reviews[1].Failed {
+		t.Errorf("expected both reviews in the same second; got %#v", reviews)
+	}
+	if stats.Reviews != 2 || stats.Lapses != 1 {
+		t.Errorf("unexpected stats %#v", stats)
+	}
+}
@@ -0,0 +1,339 @@
+package integration
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/jorinvo/slangbrain/brain/kv"
+)
+
+// All storage backends must pass the same tests.
+var kvBackends = []struct {
+	name string
+	open func(t *testing.T) (kv.DB, func())
+}{
+	{"bolt", func(t *testing.T) (kv.DB, func()) {
+		f, err := ioutil.TempFile("", "slangbrain-test")
+		fatal(t, err)
+		fatal(t, f.Close())
+		db, err := kv.OpenBolt(f.Name(), 0600)
+		fatal(t, err)
+		return db, func() {
+			fatal(t, os.Remove(f.Name()))
+		}
+	}},
+	{"memory", func(t *testing.T) (kv.DB, func()) {
+		return kv.NewMemory(), func() {}
+	}},
+}
+
+func TestKV(t *testing.T) {
+	for _, backend := range kvBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			db, cleanup := backend.open(t)
+			defer cleanup()
+			testKV(t, db)
+			fatal(t, db.Close())
+			if err := db.View(func(tx kv.Tx) error { return nil }); err != kv.ErrDatabaseNotOpen {
+				t.Errorf("expected ErrDatabaseNotOpen after close; got %v", err)
+			}
+		})
+	}
+}
+
+func testKV(t *testing.T, db kv.DB) {
+	name := []byte("test")
+	keys := []string{"a", "b", "ba", "c"}
+
+	t.Run("create", func(t *testing.T) {
+		fatal(t, db.View(func(tx kv.Tx) error {
+			if tx.Bucket(name) != nil {
+				t.Error("expected missing bucket to be nil")
+			}
+			if _, err := tx.CreateBucketIfNotExists(name); err != kv.ErrTxNotWritable {
+				t.Errorf("expected ErrTxNotWritable; got %v", err)
+			}
+			if err := tx.DeleteBucket(name); err != kv.ErrTxNotWritable {
+				t.Errorf("expected ErrTxNotWritable for delete; got %v", err)
+			}
+			return nil
+		}))
+		fatal(t, db.Update(func(tx kv.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(nil); err != kv.ErrBucketNameRequired {
+				t.Errorf("expected ErrBucketNameRequired; got %v", err)
+			}
+			if err := tx.DeleteBucket([]byte("missing")); err != kv.ErrBucketNotFound {
+				t.Errorf("expected ErrBucketNotFound; got %v", err)
+			}
+			for _, n := range [][]byte{name, []byte("other"), []byte("deleted"), name} {
+				if _, err := tx.CreateBucketIfNotExists(n); err != nil {
+					return err
+				}
+			}
+			b := tx.Bucket([]byte("deleted"))
+			if err := b.Put([]byte("a"), []byte("1")); err != nil {
+				return err
+			}
+			if err := tx.DeleteBucket([]byte("deleted")); err != nil {
+				return err
+			}
+			if tx.Bucket([]byte("deleted")) != nil {
+				t.Error("expected deleted bucket to be nil")
+			}
+			var names []string
+			err := tx.ForEach(func(n []byte, b kv.Bucket) error {
+				names = append(names, string(n))
+				return nil
+			})
+			if len(names) != 2 || names[0] != "other" || names[1] != "test" {
+				t.Errorf("expected buckets other and test; got %v", names)
+			}
+			return err
+		}))
+	})
+
+	t.Run("put", func(t *testing.T) {
+		fatal(t, db.Update(func(tx kv.Tx) error {
+			b := tx.Bucket(name)
+			// Insert out of order to check sorting
+			for _, k := range []string{"c", "a", "ba", "b"} {
+				if err := b.Put([]byte(k), []byte("old")); err != nil {
+					return err
+				}
+			}
+			for _, k := range keys {
+				if err := b.Put([]byte(k), []byte(k+"1")); err != nil {
+					return err
+				}
+			}
+			if err := b.Put(nil, []byte("x")); err != kv.ErrKeyRequired {
+				t.Errorf("expected ErrKeyRequired; got %v", err)
+			}
+			return b.Put([]byte("empty"), []byte{})
+		}))
+		fatal(t, db.View(func(tx kv.Tx) error {
+			b := tx.Bucket(name)
+			if n := b.KeyN(); n != 5 {
+				t.Errorf("expected 5 keys; got %d", n)
+			}
+			if v := b.Get([]byte("ba")); string(v) != "ba1" {
+				t.Errorf("expected ba1; got %q", v)
+			}
+			if v := b.Get([]byte("missing")); v != nil {
+				t.Errorf("expected nil for missing key; got %q", v)
+			}
+			if v := b.Get([]byte("empty")); v == nil || len(v) != 0 {
+				t.Errorf("expected empty non-nil value; got %#v", v)
+			}
+			if err := b.Put([]byte("a"), []byte("x")); err != kv.ErrTxNotWritable {
+				t.Errorf("expected ErrTxNotWritable for put; got %v", err)
+			}
+			if err := b.Delete([]byte("a")); err != kv.ErrTxNotWritable {
+				t.Errorf("expected ErrTxNotWritable for delete; got %v", err)
+			}
+			if _, err := b.NextSequence(); err != kv.ErrTxNotWritable {
+				t.Errorf("expected ErrTxNotWritable for sequence; got %v", err)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("cursor", func(t *testing.T) {
+		fatal(t, db.View(func(tx kv.Tx) error {
+			c := tx.Bucket(name).Cursor()
+			expectKey := func(step string, expected string, k, v []byte) {
+				if expected == "" && k != nil || expected != "" && string(k) != expected {
+					t.Errorf("%s: expected key %q; got %q", step, expected, k)
+				}
+				if expected != "" && expected != "empty" && string(v) != expected+"1" {
+					t.Errorf("%s: expected value %q; got %q", step, expected+"1", v)
+				}
+			}
+			k, v := c.First()
+			expectKey("first", "a", k, v)
+			k, v = c.Next()
+			expectKey("next", "b", k, v)
+			k, v = c.Seek([]byte("bb"))
+			expectKey("seek between", "c", k, v)
+			k, v = c.Prev()
+			expectKey("prev", "ba", k, v)
+			k, v = c.Seek([]byte("b"))
+			expectKey("seek exact", "b", k, v)
+			k, v = c.Last()
+			expectKey("last", "empty", k, v)
+			k, v = c.Next()
+			expectKey("after last", "", k, v)
+			k, v = c.Seek([]byte("z"))
+			expectKey("seek after last", "", k, v)
+			c.First()
+			k, v = c.Prev()
+			expectKey("before first", "", k, v)
+
+			var found []string
+			err := tx.Bucket(name).ForEach(func(k, v []byte) error {
+				found = append(found, string(k))
+				return nil
+			})
+			if len(found) != 5 || found[2] != "ba" {
+				t.Errorf("expected keys in order; got %v", found)
+			}
+			stop := errors.New("stop")
+			count := 0
+			if err := tx.Bucket(name).ForEach(func(k, v []byte) error {
+				count++
+				return stop
+			}); err != stop || count != 1 {
+				t.Errorf("expected ForEach to stop at first error; got %v after %d", err, count)
+			}
+			return err
+		}))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		fatal(t, db.Update(func(tx kv.Tx) error {
+			b := tx.Bucket(name)
+			if err := b.Delete([]byte("missing")); err != nil {
+				t.Errorf("expected deleting a missing key to succeed; got %v", err)
+			}
+			if err := b.Delete([]byte("empty")); err != nil {
+				return err
+			}
+			// Deletes are visible within the transaction
+			if v := b.Get([]byte("empty")); v != nil {
+				t.Errorf("expected deleted key to be gone; got %q", v)
+			}
+			return nil
+		}))
+		fatal(t, db.View(func(tx kv.Tx) error {
+			if n := tx.Bucket(name).KeyN(); n != len(keys) {
+				t.Errorf("expected %d keys; got %d", len(keys), n)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		failed := errors.New("failed")
+		err := db.Update(func(tx kv.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists([]byte("new")); err != nil {
+				return err
+			}
+			b := tx.Bucket(name)
+			if err := b.Put([]byte("a"), []byte("changed")); err != nil {
+				return err
+			}
+			if err := b.Delete([]byte("b")); err != nil {
+				return err
+			}
+			if _, err := b.NextSequence(); err != nil {
+				return err
+			}
+			if err := tx.DeleteBucket([]byte("other")); err != nil {
+				return err
+			}
+			return failed
+		})
+		if err != failed {
+			t.Errorf("expected error of transaction; got %v", err)
+		}
+		fatal(t, db.View(func(tx kv.Tx) error {
+			b := tx.Bucket(name)
+			if tx.Bucket([]byte("new")) != nil {
+				t.Error("expected created bucket to be rolled back")
+			}
+			if tx.Bucket([]byte("other")) == nil {
+				t.Error("expected deleted bucket to be rolled back")
+			}
+			if v := b.Get([]byte("a")); string(v) != "a1" {
+				t.Errorf("expected put to be rolled back; got %q", v)
+			}
+			if v := b.Get([]byte("b")); string(v) != "b1" {
+				t.Errorf("expected delete to be rolled back; got %q", v)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("handles", func(t *testing.T) {
+		// Handles and cursors opened before a write see it
+		failed := errors.New("failed")
+		err := db.Update(func(tx kv.Tx) error {
+			b1 := tx.Bucket(name)
+			b2 := tx.Bucket(name)
+			c := b2.Cursor()
+			if k, _ := c.First(); string(k) != "a" {
+				t.Errorf("expected cursor at first key; got %q", k)
+			}
+			if err := b1.Put([]byte("a"), []byte("changed")); err != nil {
+				return err
+			}
+			if v := b2.Get([]byte("a")); string(v) != "changed" {
+				t.Errorf("expected put to be visible in other handle; got %q", v)
+			}
+			if k, v := c.Seek([]byte("a")); string(k) != "a" || string(v) != "changed" {
+				t.Errorf("expected put to be visible in cursor; got %q: %q", k, v)
+			}
+			return failed
+		})
+		if err != failed {
+			t.Errorf("expected error of transaction; got %v", err)
+		}
+		fatal(t, db.View(func(tx kv.Tx) error {
+			if v := tx.Bucket(name).Get([]byte("a")); string(v) != "a1" {
+				t.Errorf("expected put to be rolled back; got %q", v)
+			}
+			return nil
+		}))
+	})
+
+	t.Run("sequence", func(t *testing.T) {
+		var seqs []uint64
+		for i := 0; i < 2; i++ {
+			fatal(t, db.Update(func(tx kv.Tx) error {
+				n, err := tx.Bucket(name).NextSequence()
+				seqs = append(seqs, n)
+				return err
+			}))
+		}
+		// The sequence of the rolled back transaction is not used
+		if seqs[0] != 1 || seqs[1] != 2 {
+			t.Errorf("expected sequences 1 and 2; got %v", seqs)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		fatal(t, db.View(func(tx kv.Tx) error {
+			if tx.Size() <= 0 {
+				t.Errorf("expected positive size; got %d", tx.Size())
+			}
+			return nil
+		}))
+	})
+
+	t.Run("collect and delete", func(t *testing.T) {
+		// Collect keys first, since deleting while iterating isn't safe for all backends
+		fatal(t, db.Update(func(tx kv.Tx) error {
+			b := tx.Bucket(name)
+			var toDelete [][]byte
+			c := b.Cursor()
+			for k, _ := c.Seek([]byte("b")); k != nil && bytes.HasPrefix(k, []byte("b")); k, _ = c.Next() {
+				toDelete = append(toDelete, append([]byte{}, k...))
+			}
+			for _, k := range toDelete {
+				if err := b.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		}))
+		fatal(t, db.View(func(tx kv.Tx) error {
+			if n := tx.Bucket(name).KeyN(); n != 2 {
+				t.Errorf("expected 2 keys left; got %d", n)
+			}
+			return nil
+		}))
+	})
+}
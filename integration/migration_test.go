@@ -0,0 +1,157 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/brain/bucket"
+	"github.com/jorinvo/slangbrain/brain/kv"
+	"github.com/jorinvo/slangbrain/brain/migration"
+)
+
+func TestMigrationFresh(t *testing.T) {
+	db := kv.NewMemory()
+	store, err := brain.Open(db)
+	fatal(t, err)
+	var buf bytes.Buffer
+	from, to, err := brain.Migrate(db, true, log.New(&buf, "", 0))
+	fatal(t, err)
+	if from != len(migration.All) || to != len(migration.All) || buf.Len() != 0 {
+		t.Errorf("expected fresh database to be at version %d; got %d to %d: %s", len(migration.All), from, to, buf.String())
+	}
+	fatal(t, store.Close())
+}
+
+func TestMigrationOldBackup(t *testing.T) {
+	// Integers were stored as varint in 8 bytes
+	varint := func(v int64) []byte {
+		b := make([]byte, 8)
+		binary.PutVarint(b, v)
+		return b
+	}
+	db := kv.NewMemory()
+	fatal(t, db.Update(func(tx kv.Tx) error {
+		data := map[string][][2][]byte{
+			string(bucket.Phrases): {{append(varint(123), varint(1)...), []byte(`{"Phrase":"hola","Explanation":"hello"}`)}},
+			string(bucket.Modes):   {{varint(123), varint(0)}},
+			string(bucket.Reads):   {{varint(123), varint(time.Now().Unix())}},
+		}
+		for name, entries := range data {
+			b, err := tx.CreateBucketIfNotExists([]byte(name))
+			if err != nil {
+				return err
+			}
+			for _, e := range entries {
+				if err := b.Put(e[0], e[1]); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}))
+
+	var buf bytes.Buffer
+	logger := log.New(&buf, "", 0)
+	from, to, err := brain.Migrate(db, true, logger)
+	fatal(t, err)
+	if from != 0 || to != len(migration.All) {
+		t.Errorf("expected dry run from 0 to %d; got %d to %d", len(migration.All), from, to)
+	}
+	if !strings.Contains(buf.String(), "001_phrases_json_to_gob: converted 1 phrases") {
+		t.Errorf("expected changes to be logged; got %s", buf.String())
+	}
+	// Dry run doesn't change anything
+	fatal(t, db.View(func(tx kv.Tx) error {
+		if tx.Bucket(bucket.Meta) != nil || tx.Bucket(bucket.Phrases).Get(append(varint(123), varint(1)...)) == nil {
+			t.Error("expected dry run to be rolled back")
+		}
+		return nil
+	}))
+
+	buf.Reset()
+	store, err := brain.Open(db, brain.LogInfo(logger))
+	fatal(t, err)
+	defer func() {
+		fatal(t, store.Close())
+	}()
+	for _, m := range migration.All {
+		if !strings.Contains(buf.String(), "migration "+m.Name) {
+			t.Errorf("expected migration %s to be applied; got %s", m.Name, buf.String())
+		}
+	}
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	if len(phrases) != 1 || phrases[0].Phrase != "hola" || phrases[0].ID != 1 {
+		t.Errorf("expected migrated phrase; got %#v", phrases)
+	}
+	if p, err := store.FindExplanation(123, "hello"); err != nil || p.Phrase != "hola" {
+		t.Errorf("expected explanation to be indexed; got %#v, %v", p, err)
+	}
+	if _, err := store.GetStudy(123); err != nil {
+		t.Errorf("expected migrated phrase to be studied; got %v", err)
+	}
+
+	from, _, err = brain.Migrate(db, true, logger)
+	fatal(t, err)
+	if from != len(migration.All) {
+		t.Errorf("expected database to be at version %d; got %d", len(migration.All), from)
+	}
+}
+
+func TestMigrationNewer(t *testing.T) {
+	db := kv.NewMemory()
+	fatal(t, db.Update(func(tx kv.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket.Meta)
+		if err != nil {
+			return err
+		}
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(len(migration.All)+1))
+		return b.Put([]byte("version"), v)
+	}))
+	if _, err := brain.Open(db); err == nil {
+		t.Error("expected newer database version to fail")
+	}
+}
+
+func TestMigrationNoMigrate(t *testing.T) {
+	db := kv.NewMemory()
+	version := func() uint64 {
+		var v uint64
+		fatal(t, db.View(func(tx kv.Tx) error {
+			v = binary.BigEndian.Uint64(tx.Bucket(bucket.Meta).Get([]byte("version")))
+			return nil
+		}))
+		return v
+	}
+	fatal(t, db.Update(func(tx kv.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(bucket.Meta)
+		if err != nil {
+			return err
+		}
+		v := make([]byte, 8)
+		binary.BigEndian.PutUint64(v, uint64(len(migration.All)-1))
+		return b.Put([]byte("version"), v)
+	}))
+
+	if _, err := brain.Open(db, brain.NoMigrate); err == nil {
+		t.Error("expected database with pending migrations to fail")
+	}
+	if v := version(); v != uint64(len(migration.All)-1) {
+		t.Errorf("expected database not to be migrated; got version %d", v)
+	}
+
+	_, err := brain.Open(db)
+	fatal(t, err)
+	store, err := brain.Open(db, brain.NoMigrate)
+	fatal(t, err)
+	if _, err := store.Check(false); err != nil {
+		t.Errorf("expected migrated database to be readable; got %v", err)
+	}
+	fatal(t, store.Close())
+}
@@ -27,27 +27,27 @@ func TestNotify(t *testing.T) {
 		},
 		{
 			name:   "help 1",
-			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"},{"content_type":"text","title":"Vorschau","payload":"PAYLOAD_FORECAST"}]}}`,
 			send:   fmt.Sprintf(formatPayload, payload.Subscribe),
 		},
 		{
 			name:   "subscribed",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Ich schicke dir eine Nachricht sobald es etwas zu wiederholen gibt.\n\nWas willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Ich schicke dir eine Nachricht sobald es etwas zu wiederholen gibt.\n\nWas willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 Tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
 			send:   fmt.Sprintf(formatPayload, payload.Help),
 		},
 		{
 			name:   "help 2",
-			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"❌ Benachrichtigung","payload":"PAYLOAD_UNSUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"❌ Benachrichtigung","payload":"PAYLOAD_UNSUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"},{"content_type":"text","title":"Vorschau","payload":"PAYLOAD_FORECAST"}]}}`,
 			send:   fmt.Sprintf(formatPayload, payload.Unsubscribe),
 		},
 		{
 			name:   "unsubscribed",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Alles klar, du bekommst in Zukunft keine Benachrichtigungen mehr.\n\nWas willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Alles klar, du bekommst in Zukunft keine Benachrichtigungen mehr.\n\nWas willst du als nächstes machen?","quick_replies":[{"content_type":"text","title":"🏫 lernen","payload":"PAYLOAD_STARTSTUDY"},{"content_type":"text","title":"🏷 Tags","payload":"PAYLOAD_SHOWTAGS"},{"content_type":"text","title":"➕ neu","payload":"PAYLOAD_STARTADD"},{"content_type":"text","title":"❓ Hilfe","payload":"PAYLOAD_SHOWHELP"},{"content_type":"text","title":"✔ fertig","payload":"PAYLOAD_IDLE"}]}}`,
 			send:   fmt.Sprintf(formatPayload, payload.Help),
 		},
 		{
 			name:   "help 3",
-			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"attachment":{"type":"template","payload":{"template_type":"button","text":"Wie kann ich dir weiterhelfen?","buttons":[{"type":"web_url","title":"slangbrain.com","url":"https://slangbrain.com/de/blog/","webview_share_button":"hide"}]}},"quick_replies":[{"content_type":"text","title":"zurück","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"✔ Benachrichtigung","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"Feedback geben","payload":"PAYLOAD_FEEDBACK"},{"content_type":"text","title":"Vokabeln importieren","payload":"PAYLOAD_IMPORTHELP"},{"content_type":"text","title":"API Token","payload":"PAYLOAD_GETTOKEN"},{"content_type":"text","title":"Vorschau","payload":"PAYLOAD_FORECAST"}]}}`,
 		},
 	}
 
@@ -0,0 +1,294 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestQueryPhrases(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	s := brain.DefaultSettings
+	s.NewPhrases = 2
+	fatal(t, store.SetSettings(123, s))
+	start := time.Now().Add(-24 * time.Hour)
+	for i := 1; i <= 5; i++ {
+		fatal(t, store.AddPhrase(123, fmt.Sprintf("phrase%d", i), fmt.Sprintf("explanation%d", i), start.Add(time.Duration(i)*time.Minute)))
+	}
+	// Only the first two phrases are scheduled.
+	// Studying one gives it a score and schedules the third one.
+	_, err := store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+
+	query := func(q brain.PhraseQuery) ([]string, string) {
+		phrases, next, err := store.QueryPhrases(123, q)
+		fatal(t, err)
+		var names []string
+		for _, p := range phrases {
+			names = append(names, p.Phrase)
+		}
+		return names, next
+	}
+	one := 1
+
+	tt := []struct {
+		name   string
+		query  brain.PhraseQuery
+		expect []string
+	}{
+		{"added", brain.PhraseQuery{}, []string{"phrase5", "phrase4", "phrase3", "phrase2", "phrase1"}},
+		{"score", brain.PhraseQuery{Sort: brain.SortScore, MinScore: &one}, []string{"phrase1"}},
+		{"due", brain.PhraseQuery{Sort: brain.SortDue, Limit: 2}, []string{"phrase2", "phrase1"}},
+		{"due now", brain.PhraseQuery{Due: brain.DueNow}, []string{"phrase2"}},
+		{"due later", brain.PhraseQuery{Due: brain.DueLater}, []string{"phrase3", "phrase1"}},
+		{"new", brain.PhraseQuery{Due: brain.DueNew, MaxScore: &one}, []string{"phrase5", "phrase4"}},
+		{"search", brain.PhraseQuery{Search: "EXPLANATION3"}, []string{"phrase3"}},
+	}
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			if names, _ := query(tc.query); !reflect.DeepEqual(names, tc.expect) {
+				t.Errorf("expected %v; got %v", tc.expect, names)
+			}
+		})
+	}
+
+	t.Run("pages", func(t *testing.T) {
+		// Pages of one phrase follow the same order as a single page
+		for _, s := range []brain.PhraseSort{brain.SortAdded, brain.SortScore, brain.SortDue} {
+			all, _ := query(brain.PhraseQuery{Sort: s})
+			var paged []string
+			q := brain.PhraseQuery{Sort: s, Limit: 1}
+			for {
+				names, next := query(q)
+				paged = append(paged, names...)
+				if next == "" {
+					break
+				}
+				q.Cursor = next
+			}
+			if !reflect.DeepEqual(paged, all) {
+				t.Errorf("expected pages of sort %d to be %v; got %v", s, all, paged)
+			}
+		}
+
+		q := brain.PhraseQuery{Limit: 2}
+		var pages [][]string
+		for {
+			names, next := query(q)
+			pages = append(pages, names)
+			if next == "" {
+				break
+			}
+			q.Cursor = next
+		}
+		expect := [][]string{{"phrase5", "phrase4"}, {"phrase3", "phrase2"}, {"phrase1"}}
+		if !reflect.DeepEqual(pages, expect) {
+			t.Errorf("expected pages %v; got %v", expect, pages)
+		}
+		if _, _, err := store.QueryPhrases(123, brain.PhraseQuery{Cursor: "nope"}); err != brain.ErrInvalidCursor {
+			t.Errorf("expected ErrInvalidCursor; got %v", err)
+		}
+	})
+
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	errLogger := log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)
+
+	t.Run("api", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.Handle("/api/phrases", http.StripPrefix("/api/phrases", api.Phrases(store, errLogger)))
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		var names []string
+		link := "/api/phrases?token=" + apiToken + "&limit=3&sort=score"
+		for link != "" {
+			res, err := http.Get(ts.URL + link)
+			fatal(t, err)
+			var data struct {
+				Data []brain.IDPhrase `json:"data"`
+			}
+			fatal(t, json.NewDecoder(res.Body).Decode(&data))
+			fatal(t, res.Body.Close())
+			for _, p := range data.Data {
+				names = append(names, p.Phrase)
+			}
+			link = strings.TrimSuffix(strings.TrimPrefix(res.Header.Get("Link"), "<"), `>; rel="next"`)
+		}
+		if expect := []string{"phrase2", "phrase3", "phrase4", "phrase5", "phrase1"}; !reflect.DeepEqual(names, expect) {
+			t.Errorf("expected %v; got %v", expect, names)
+		}
+
+		res, err := http.Get(ts.URL + "/api/phrases?token=" + apiToken + "&sort=nope")
+		fatal(t, err)
+		if res.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected invalid sort to fail; got %d", res.StatusCode)
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		ts := httptest.NewServer(api.CSV(store, errLogger))
+		defer ts.Close()
+
+		// Tags are in separate columns and can contain commas
+		fatal(t, store.SetTags(123, 5, []string{"a, b", "c"}))
+		res, err := http.Get(ts.URL + "?token=" + apiToken + "&due=new&limit=2")
+		fatal(t, err)
+		r := csv.NewReader(res.Body)
+		r.FieldsPerRecord = -1
+		records, err := r.ReadAll()
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		expect := [][]string{{"phrase5", "explanation5", "", "a, b", "c"}, {"phrase4", "explanation4"}}
+		if !reflect.DeepEqual(records, expect) {
+			t.Errorf("expected %v; got %v", expect, records)
+		}
+	})
+}
+
+func TestCSVPages(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	var phrases []brain.Phrase
+	for i := 0; i < 250; i++ {
+		phrases = append(phrases, brain.Phrase{Phrase: fmt.Sprintf("phrase%d", i), Explanation: fmt.Sprintf("explanation%d", i)})
+	}
+	_, err := store.Import(123, phrases)
+	fatal(t, err)
+	// Phrases with several cards and different scores are placed once in each order
+	for i := 1; i <= 30; i++ {
+		fatal(t, store.SetDirection(123, i, brain.DirectionBoth))
+	}
+	for i := 0; i < 10; i++ {
+		_, err := store.ScoreStudy(123, brain.GradeGood)
+		fatal(t, err)
+	}
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(api.CSV(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)))
+	defer ts.Close()
+
+	// Phrases are read in pages of 100
+	for _, tc := range []struct {
+		query  string
+		expect int
+	}{{"", 250}, {"&limit=150", 150}, {"&limit=200", 200}, {"&sort=score", 250}, {"&sort=due", 250}, {"&sort=due&limit=120", 120}} {
+		res, err := http.Get(ts.URL + "?token=" + apiToken + tc.query)
+		fatal(t, err)
+		records, err := csv.NewReader(res.Body).ReadAll()
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		seen := map[string]bool{}
+		for _, r := range records {
+			seen[r[0]] = true
+		}
+		if len(records) != tc.expect || len(seen) != tc.expect {
+			t.Errorf("expected %d distinct phrases for %q; got %d records, %d distinct", tc.expect, tc.query, len(records), len(seen))
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	// Text in |...| is ignored when checking answers and must not be mistaken for alternatives
+	phrases := []brain.Phrase{
+		{Phrase: "|hint| palabra", Explanation: "word", Alternatives: []string{"la |f| palabra", "vocablo"}, Tags: []string{"a, b"}},
+		{Phrase: "hola", Explanation: "hello", Tags: []string{"greetings"}},
+		{Phrase: "adiós", Explanation: "bye"},
+	}
+	_, err := store.Import(123, phrases)
+	fatal(t, err)
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	csvServer := httptest.NewServer(api.CSV(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)))
+	defer csvServer.Close()
+
+	// Fake the Facebook server, which also serves the exported file
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/phrases.csv" {
+			res, err := http.Get(csvServer.URL + "?token=" + apiToken)
+			fatal(t, err)
+			defer res.Body.Close()
+			io.Copy(w, res.Body)
+			return
+		}
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{ "locale": "en_GB" }`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	send(t, b, fmt.Sprintf(formatUserMessage, 456, "1", ts.URL+"/phrases.csv"))
+	count, err := store.ApplyImport(456)
+	fatal(t, err)
+	if count != len(phrases) {
+		t.Fatalf("expected %d phrases to be imported; got %d", len(phrases), count)
+	}
+	imported, err := store.GetAllPhrases(456)
+	fatal(t, err)
+	for i, p := range imported {
+		expect := phrases[i]
+		if p.Phrase != expect.Phrase || p.Explanation != expect.Explanation || !reflect.DeepEqual(p.Alternatives, expect.Alternatives) || !reflect.DeepEqual(p.Tags, expect.Tags) {
+			t.Errorf("expected phrase %#v; got %#v", expect, p)
+		}
+	}
+}
+
+func TestUpdatePhraseAPI(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now()))
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(http.StripPrefix("/", api.Phrases(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile))))
+	defer ts.Close()
+
+	put := func(seq int, body string) int {
+		req, err := http.NewRequest("PUT", fmt.Sprintf("%s/%d?token=%s", ts.URL, seq, apiToken), strings.NewReader(body))
+		fatal(t, err)
+		res, err := http.DefaultClient.Do(req)
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		return res.StatusCode
+	}
+
+	if code := put(2, `{ "data": { "phrase": "adiós", "explanation": "bye" } }`); code != http.StatusNotFound {
+		t.Errorf("expected unknown phrase to be not found; got %d", code)
+	}
+
+	if code := put(1, `{ "data": { "phrase": "hola", "explanation": "hi", "tags": ["greetings"], "direction": "both" } }`); code != http.StatusOK {
+		t.Errorf("expected update to succeed; got %d", code)
+	}
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	if phrases[0].Explanation != "hi" || !reflect.DeepEqual(phrases[0].Tags, []string{"greetings"}) || phrases[0].Direction != brain.DirectionBoth {
+		t.Errorf("expected phrase to be updated; got %#v", phrases[0])
+	}
+}
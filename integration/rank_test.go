@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestRank(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	yesterday := time.Now().Add(-24 * time.Hour)
+	for id, scores := range map[int64][]brain.Grade{
+		1: {brain.GradeGood},
+		2: {brain.GradeEasy},
+		3: {},
+	} {
+		fatal(t, store.Register(id))
+		fatal(t, store.AddPhrase(id, "hola", "hello", yesterday))
+		for _, grade := range scores {
+			_, err := store.ScoreStudy(id, grade)
+			fatal(t, err)
+		}
+	}
+	// Score total of 0 counts as well
+	fatal(t, store.AddPhrase(3, "adiós", "bye", yesterday))
+	phrases, err := store.GetAllPhrases(3)
+	fatal(t, err)
+	fatal(t, store.DeletePhrase(3, int(phrases[0].ID)))
+
+	for id, expected := range map[int64]brain.Stats{
+		1: {Score: 1, Rank: 2, Percentile: 66, MonthRank: 2},
+		2: {Score: 2, Rank: 1, Percentile: 100, MonthRank: 1},
+		3: {Score: 0, Rank: 3, Percentile: 33, MonthRank: 3},
+	} {
+		s, err := store.GetRank(id)
+		fatal(t, err)
+		if s != expected {
+			t.Errorf("expected rank of %d to be %#v; got %#v", id, expected, s)
+		}
+	}
+
+	// Ranks move with deleted phrases
+	phrases, err = store.GetAllPhrases(2)
+	fatal(t, err)
+	fatal(t, store.DeletePhrase(2, int(phrases[0].ID)))
+	s, err := store.GetRank(1)
+	fatal(t, err)
+	if s.Rank != 1 {
+		t.Errorf("expected rank 1 after other user lost score; got %#v", s)
+	}
+}
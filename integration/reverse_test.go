@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestReverse(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	_, err := store.Import(123, []brain.Phrase{{Phrase: "hola", Explanation: "hello", Direction: brain.DirectionBoth}})
+	fatal(t, err)
+	fatal(t, store.AddPhrase(123, "gracias", "thanks", time.Now()))
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "correct",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Correct!"}}`,
+		},
+		{
+			name:   "reverse",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know what this means?\n\nhola\n\nUse the buttons or type the explanation.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatMessage, "2", "bye"),
+		},
+		{
+			name:   "wrong",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Sorry, the right version is:\n\nhello"}}`,
+		},
+		{
+			name:   "done",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nStudied: 2\nCorrect: 50%\nLearned: 1\nHardest: hola\nStreak: 1 day\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatMessage, "1", "hola"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+
+	// Removing the reverse card keeps the forward card
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		if p.Phrase != "hola" {
+			continue
+		}
+		fatal(t, store.SetDirection(123, int(p.ID), brain.DirectionForward))
+	}
+	phrases, err = store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		if p.Phrase == "hola" && (p.Direction != brain.DirectionForward || p.Score != 1 || p.ReverseScore != 0) {
+			t.Errorf("expected forward phrase with score 1; got %#v", p)
+		}
+	}
+}
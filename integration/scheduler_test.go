@@ -0,0 +1,167 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestSwitchScheduler(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	name, err := store.GetScheduler(123)
+	fatal(t, err)
+	if name != brain.SchedulerTable {
+		t.Errorf("expected default scheduler to be %s; got %s", brain.SchedulerTable, name)
+	}
+
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", time.Now().Add(-24*time.Hour)))
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	before, err := store.GetStudy(123)
+	fatal(t, err)
+
+	if err := store.SetScheduler(123, "unknown"); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for unknown scheduler; got %v", err)
+	}
+
+	for _, s := range []string{brain.SchedulerSM2, brain.SchedulerFSRS, brain.SchedulerTable} {
+		fatal(t, store.SetScheduler(123, s))
+		name, err := store.GetScheduler(123)
+		fatal(t, err)
+		if name != s {
+			t.Errorf("expected scheduler to be %s; got %s", s, name)
+		}
+		after, err := store.GetStudy(123)
+		fatal(t, err)
+		if diff := before.Next - after.Next; diff < 0 || diff > time.Minute {
+			t.Errorf("expected study time to be kept when switching to %s; got %v before and %v after", s, before.Next, after.Next)
+		}
+	}
+}
+
+func TestSchedulerIntervals(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	day := 24 * time.Hour
+	tt := []struct {
+		scheduler string
+		grade     brain.Grade
+		// Intervals of three reviews in a row
+		expect []time.Duration
+	}{
+		{brain.SchedulerSM2, brain.GradeAgain, []time.Duration{time.Hour, time.Hour, time.Hour}},
+		{brain.SchedulerSM2, brain.GradeHard, []time.Duration{day, 6 * day, time.Duration(6 * 2.08 * float64(day))}},
+		{brain.SchedulerSM2, brain.GradeGood, []time.Duration{day, 6 * day, 15 * day}},
+		{brain.SchedulerSM2, brain.GradeEasy, []time.Duration{day, 6 * day, time.Duration(6 * 2.8 * float64(day))}},
+		// Stability doesn't grow when reviewing again right away
+		{brain.SchedulerFSRS, brain.GradeAgain, []time.Duration{time.Hour, time.Hour, time.Hour}},
+		{brain.SchedulerFSRS, brain.GradeHard, []time.Duration{time.Duration(0.6 * float64(day)), time.Duration(0.6 * float64(day)), time.Duration(0.6 * float64(day))}},
+		{brain.SchedulerFSRS, brain.GradeGood, []time.Duration{time.Duration(2.4 * float64(day)), time.Duration(2.4 * float64(day)), time.Duration(2.4 * float64(day))}},
+		{brain.SchedulerFSRS, brain.GradeEasy, []time.Duration{time.Duration(5.8 * float64(day)), time.Duration(5.8 * float64(day)), time.Duration(5.8 * float64(day))}},
+	}
+	for i, tc := range tt {
+		id := int64(100 + i)
+		t.Run(fmt.Sprintf("%s %d", tc.scheduler, tc.grade), func(t *testing.T) {
+			fatal(t, store.SetScheduler(id, tc.scheduler))
+			fatal(t, store.AddPhrase(id, "phrase1", "explanation1", time.Now().Add(-24*time.Hour)))
+			for j, expected := range tc.expect {
+				_, err := store.ScoreStudy(id, tc.grade)
+				fatal(t, err)
+				study, err := store.GetStudy(id)
+				fatal(t, err)
+				expectInterval(t, study.Next, expected, fmt.Sprintf("review %d", j+1))
+			}
+		})
+	}
+}
+
+func TestSchedulerSetting(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	s, err := store.GetSettings(123)
+	fatal(t, err)
+	if s.Scheduler != brain.SchedulerTable {
+		t.Errorf("expected default scheduler %s; got %s", brain.SchedulerTable, s.Scheduler)
+	}
+
+	// Choose scheduler via API
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(api.Settings(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)))
+	defer ts.Close()
+	put := func(body string) int {
+		req, err := http.NewRequest("PUT", ts.URL+"?token="+apiToken, strings.NewReader(body))
+		fatal(t, err)
+		res, err := http.DefaultClient.Do(req)
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		return res.StatusCode
+	}
+
+	if code := put(`{ "data": { "scheduler": "fsrs" } }`); code != http.StatusOK {
+		t.Errorf("expected scheduler to be updated; got %d", code)
+	}
+	name, err := store.GetScheduler(123)
+	fatal(t, err)
+	if name != brain.SchedulerFSRS {
+		t.Errorf("expected scheduler %s; got %s", brain.SchedulerFSRS, name)
+	}
+	s, err = store.GetSettings(123)
+	fatal(t, err)
+	if s.Scheduler != brain.SchedulerFSRS {
+		t.Errorf("expected settings to contain scheduler %s; got %s", brain.SchedulerFSRS, s.Scheduler)
+	}
+
+	if code := put(`{ "data": { "scheduler": "nope" } }`); code != http.StatusBadRequest {
+		t.Errorf("expected unknown scheduler to fail; got %d", code)
+	}
+	// Other settings keep the scheduler
+	if code := put(`{ "data": { "newPhrases": 3 } }`); code != http.StatusOK {
+		t.Errorf("expected settings to be updated; got %d", code)
+	}
+	if name, _ := store.GetScheduler(123); name != brain.SchedulerFSRS {
+		t.Errorf("expected scheduler to be kept; got %s", name)
+	}
+}
+
+func TestIntervalMultiplier(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	s := brain.DefaultSettings
+	s.IntervalMultiplier = 2
+	fatal(t, store.SetSettings(123, s))
+	fatal(t, store.SetScheduler(123, brain.SchedulerSM2))
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", time.Now().Add(-24*time.Hour)))
+
+	// SM-2 multiplies the previous interval by the ease,
+	// the multiplier must only be applied once to each interval
+	day := 24 * time.Hour
+	for i, expected := range []time.Duration{day, 6 * day, 15 * day, 37*day + 12*time.Hour} {
+		_, err := store.ScoreStudy(123, brain.GradeGood)
+		fatal(t, err)
+		study, err := store.GetStudy(123)
+		fatal(t, err)
+		expectInterval(t, study.Next, 2*expected, fmt.Sprintf("review %d", i+1))
+	}
+}
+
+// Study times are diffused by up to 20% and stored in seconds.
+func expectInterval(t *testing.T, got, expected time.Duration, name string) {
+	t.Helper()
+	if got < expected-time.Minute || got > expected+expected/5+time.Minute {
+		t.Errorf("%s: expected interval of %v; got %v", name, expected, got)
+	}
+}
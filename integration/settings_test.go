@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestSettings(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	s, err := store.GetSettings(123)
+	fatal(t, err)
+	if s != brain.DefaultSettings {
+		t.Errorf("expected default settings; got %#v", s)
+	}
+
+	s.NewPhrases = 1
+	fatal(t, store.SetSettings(123, s))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase2", "explanation2", yesterday))
+	study, err := store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 1 {
+		t.Errorf("expected only one new phrase to be scheduled; got %#v", study)
+	}
+
+	// Zero values are kept
+	zero := s
+	zero.NewPhrases = 0
+	zero.QuietStart = 0
+	zero.QuietEnd = 0
+	fatal(t, store.SetSettings(124, zero))
+	if got, err := store.GetSettings(124); err != nil || got != zero {
+		t.Errorf("expected settings %#v; got %#v, %v", zero, got, err)
+	}
+
+	s.IntervalMultiplier = 0
+	if err := store.SetSettings(123, s); err == nil {
+		t.Error("expected invalid settings to fail")
+	}
+
+	// Change settings via API
+	apiToken, err := store.GenerateToken(123)
+	fatal(t, err)
+	ts := httptest.NewServer(api.Settings(store, log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)))
+	defer ts.Close()
+
+	req, err := http.NewRequest("PUT", ts.URL+"?token="+apiToken, strings.NewReader(`{ "data": { "newPhrases": 2, "quietStart": 22 } }`))
+	fatal(t, err)
+	res, err := http.DefaultClient.Do(req)
+	fatal(t, err)
+	if res.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(res.Body)
+		t.Errorf("expected settings to be updated; got %d: %s", res.StatusCode, body)
+	}
+
+	res, err = http.Get(ts.URL + "?token=" + apiToken)
+	fatal(t, err)
+	var data struct {
+		Data brain.Settings `json:"data"`
+	}
+	fatal(t, json.NewDecoder(res.Body).Decode(&data))
+	expected := brain.DefaultSettings
+	expected.NewPhrases = 2
+	expected.QuietStart = 22
+	if data.Data != expected {
+		t.Errorf("expected settings %#v; got %#v", expected, data.Data)
+	}
+
+	// Increasing the limit schedules waiting new phrases
+	_, count, err := store.GetNotifyTime(123, 0)
+	fatal(t, err)
+	if count != 2 {
+		t.Errorf("expected both phrases to be scheduled; got %d", count)
+	}
+}
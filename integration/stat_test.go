@@ -0,0 +1,23 @@
+package integration
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStat(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.Register(123))
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now()))
+	// Empty explanations are not indexed and don't need a warning
+	fatal(t, store.AddPhrase(123, "adiós", "", time.Now()))
+
+	var buf bytes.Buffer
+	fatal(t, store.WriteStat(&buf))
+	if strings.Contains(buf.String(), "WARNING") {
+		t.Errorf("expected no warnings; got %s", buf.String())
+	}
+}
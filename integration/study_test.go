@@ -39,7 +39,7 @@ func TestStudy(t *testing.T) {
 		},
 		{
 			name:   "review 2",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"5. Do you know how to say this?\n\nexplanation2\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"5. Do you know how to say this?\n\nexplanation2\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
 			send:   fmt.Sprintf(formatMessage, "2", "wrong"),
 		},
 		{
@@ -48,17 +48,17 @@ func TestStudy(t *testing.T) {
 		},
 		{
 			name:   "review 3",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"4. Do you know how to say this?\n\nexplanation3\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"4. Do you know how to say this?\n\nexplanation3\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SHOWSTUDY"),
 		},
 		{
-			name:   "score bad",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase3","quick_replies":[{"content_type":"text","title":"👎 didn't know","payload":"PAYLOAD_SCOREBAD"},{"content_type":"text","title":"🤔","payload":"PAYLOAD_SCOREOK"},{"content_type":"text","title":"👌 got it","payload":"PAYLOAD_SCOREGOOD"}]}}`,
-			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREBAD"),
+			name:   "score again",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase3","quick_replies":[{"content_type":"text","title":"👎 again","payload":"PAYLOAD_SCOREAGAIN"},{"content_type":"text","title":"🤔 hard","payload":"PAYLOAD_SCOREHARD"},{"content_type":"text","title":"👌 good","payload":"PAYLOAD_SCOREGOOD"},{"content_type":"text","title":"💯 easy","payload":"PAYLOAD_SCOREEASY"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREAGAIN"),
 		},
 		{
 			name:   "review 4",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"3. Do you know how to say this?\n\nexplanation4\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"3. Do you know how to say this?\n\nexplanation4\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
 			send:   fmt.Sprintf(formatMessage, "3", "\\n\\nphrase4\\n\\n"),
 		},
 		{
@@ -67,27 +67,27 @@ func TestStudy(t *testing.T) {
 		},
 		{
 			name:   "review 5",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"2. Do you know how to say this?\n\nexplanation5\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"2. Do you know how to say this?\n\nexplanation5\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SHOWSTUDY"),
 		},
 		{
-			name:   "score ok",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase5","quick_replies":[{"content_type":"text","title":"👎 didn't know","payload":"PAYLOAD_SCOREBAD"},{"content_type":"text","title":"🤔","payload":"PAYLOAD_SCOREOK"},{"content_type":"text","title":"👌 got it","payload":"PAYLOAD_SCOREGOOD"}]}}`,
-			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREOK"),
+			name:   "score hard",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase5","quick_replies":[{"content_type":"text","title":"👎 again","payload":"PAYLOAD_SCOREAGAIN"},{"content_type":"text","title":"🤔 hard","payload":"PAYLOAD_SCOREHARD"},{"content_type":"text","title":"👌 good","payload":"PAYLOAD_SCOREGOOD"},{"content_type":"text","title":"💯 easy","payload":"PAYLOAD_SCOREEASY"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREHARD"),
 		},
 		{
 			name:   "review 6",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nexplanation6\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nexplanation6\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SHOWSTUDY"),
 		},
 		{
 			name:   "score good",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase6","quick_replies":[{"content_type":"text","title":"👎 didn't know","payload":"PAYLOAD_SCOREBAD"},{"content_type":"text","title":"🤔","payload":"PAYLOAD_SCOREOK"},{"content_type":"text","title":"👌 got it","payload":"PAYLOAD_SCOREGOOD"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"phrase6","quick_replies":[{"content_type":"text","title":"👎 again","payload":"PAYLOAD_SCOREAGAIN"},{"content_type":"text","title":"🤔 hard","payload":"PAYLOAD_SCOREHARD"},{"content_type":"text","title":"👌 good","payload":"PAYLOAD_SCOREGOOD"},{"content_type":"text","title":"💯 easy","payload":"PAYLOAD_SCOREEASY"}]}}`,
 			send:   fmt.Sprintf(formatPayload, "PAYLOAD_SCOREGOOD"),
 		},
 		{
 			name:   "done",
-			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Congrats, you finished all your studies for now!\nCome back in an hour.\n\nStudied: 6\nCorrect: 66%\nLearned: 3\nHardest: phrase2, phrase3\nStreak: 1 day\n\nWould you like me to send you a message when there are phrases ready for studying?","quick_replies":[{"content_type":"text","title":"👌 sounds good","payload":"PAYLOAD_SUBSCRIBE"},{"content_type":"text","title":"no thanks","payload":"PAYLOAD_NOSUBSCRIPTION"}]}}`,
 		},
 	}
 
@@ -0,0 +1,28 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestSummary(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now().Add(-time.Hour)))
+
+	// Both answers are given in the same second
+	_, err := store.ScoreStudy(123, brain.GradeAgain)
+	fatal(t, err)
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+
+	s, err := store.GetSummary(123)
+	fatal(t, err)
+	expected := brain.Summary{Studied: 2, Correct: 1, Learned: 1, Hardest: []string{"hola"}, Streak: 1}
+	if !reflect.DeepEqual(s, expected) {
+		t.Errorf("expected summary %#v; got %#v", expected, s)
+	}
+}
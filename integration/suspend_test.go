@@ -0,0 +1,92 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestSuspendAndBury(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase2", "explanation2", yesterday))
+	ids := map[string]int{}
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		ids[p.Phrase] = int(p.ID)
+	}
+
+	fatal(t, store.SuspendPhrase(123, ids["phrase1"], true))
+	study, err := store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 1 || study.Phrase != "phrase2" {
+		t.Errorf("expected suspended phrase to be skipped; got %#v", study)
+	}
+	_, count, err := store.GetNotifyTime(123, 0)
+	fatal(t, err)
+	if count != 1 {
+		t.Errorf("expected suspended phrase not to be counted for notifications; got %d", count)
+	}
+
+	fatal(t, store.BuryPhrase(123, ids["phrase2"], 0))
+	study, err = store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 0 || study.Next <= 0 || study.Next > 24*time.Hour {
+		t.Errorf("expected buried phrase to be studied tomorrow; got %#v", study)
+	}
+
+	fatal(t, store.SuspendPhrase(123, ids["phrase1"], false))
+	study, err = store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 1 || study.Phrase != "phrase1" {
+		t.Errorf("expected unsuspended phrase to be studied; got %#v", study)
+	}
+
+	if err := store.SuspendPhrase(123, 999, true); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound for unknown phrase; got %v", err)
+	}
+}
+
+func TestLeech(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", time.Now().Add(-24*time.Hour)))
+
+	var leech int64
+	for i := 0; leech == 0 && i < 10; i++ {
+		var err error
+		leech, err = store.ScoreStudy(123, brain.GradeAgain)
+		fatal(t, err)
+	}
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	if leech != phrases[0].ID || !phrases[0].Leech {
+		t.Errorf("expected phrase to become a leech; got %d and %#v", leech, phrases[0])
+	}
+
+	// Undoing the failure reverts the leech
+	fatal(t, store.UndoLastStudy(123))
+	phrases, err = store.GetAllPhrases(123)
+	fatal(t, err)
+	if phrases[0].Leech {
+		t.Errorf("expected undone phrase not to be a leech; got %#v", phrases[0])
+	}
+	leech, err = store.ScoreStudy(123, brain.GradeAgain)
+	fatal(t, err)
+	if leech != phrases[0].ID {
+		t.Errorf("expected phrase to become a leech again; got %d", leech)
+	}
+
+	// Updating a phrase resets the leech
+	fatal(t, store.UpdatePhrase(123, int(leech), "phrase1", "better explanation"))
+	phrases, err = store.GetAllPhrases(123)
+	fatal(t, err)
+	if phrases[0].Leech {
+		t.Errorf("expected updated phrase not to be a leech; got %#v", phrases[0])
+	}
+}
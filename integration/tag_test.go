@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestStudyTag(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	_, err := store.Import(123, []brain.Phrase{
+		{Phrase: "hola", Explanation: "hello", Tags: []string{"greetings", " spanish "}},
+		{Phrase: "gracias", Explanation: "thanks", Tags: []string{"spanish"}},
+		{Phrase: "danke", Explanation: "thanks"},
+	})
+	fatal(t, err)
+
+	tags, err := store.GetTags(123)
+	fatal(t, err)
+	if expected := []string{"greetings", "spanish"}; !reflect.DeepEqual(tags, expected) {
+		t.Errorf("expected tags %v; got %v", expected, tags)
+	}
+
+	phrases, err := store.GetPhrasesByTag(123, "greetings")
+	fatal(t, err)
+	if len(phrases) != 1 || phrases[0].Phrase != "hola" {
+		t.Errorf("expected only hola to be tagged with greetings; got %#v", phrases)
+	}
+
+	fatal(t, store.SetStudyTag(123, "greetings"))
+	study, err := store.GetStudy(123)
+	fatal(t, err)
+	if study.Explanation != "hello" || study.Total != 1 {
+		t.Errorf("expected only hola to be studied; got %#v", study)
+	}
+
+	// Removing the tag hides the phrase from studies
+	fatal(t, store.SetTags(123, int(phrases[0].ID), nil))
+	study, err = store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 0 {
+		t.Errorf("expected no studies after removing tag; got %#v", study)
+	}
+
+	fatal(t, store.SetStudyTag(123, ""))
+	study, err = store.GetStudy(123)
+	fatal(t, err)
+	if study.Total != 3 {
+		t.Errorf("expected all phrases to be studied; got %#v", study)
+	}
+}
@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/api"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestTrash(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	yesterday := time.Now().Add(-24 * time.Hour)
+	for _, p := range []string{"phrase1", "phrase2", "phrase3", "phrase4"} {
+		fatal(t, store.AddPhrase(123, p, "explanation", yesterday))
+	}
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	// Give one phrase a score and suspend another
+	_, err = store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	fatal(t, store.SuspendPhrase(123, int(phrases[1].ID), true))
+	before, err := store.GetAllPhrases(123)
+	fatal(t, err)
+
+	checkConsistent := func(t *testing.T) {
+		if problems, err := store.Check(false); err != nil || len(problems) != 0 {
+			t.Errorf("expected no problems; got %v, %v", problems, err)
+		}
+	}
+
+	t.Run("restore", func(t *testing.T) {
+		for _, p := range before {
+			fatal(t, store.DeletePhrase(123, int(p.ID)))
+		}
+		checkConsistent(t)
+		if phrases, err := store.GetAllPhrases(123); err != nil || len(phrases) != 0 {
+			t.Errorf("expected all phrases to be deleted; got %v, %v", phrases, err)
+		}
+		trash, err := store.GetTrash(123)
+		fatal(t, err)
+		if len(trash) != len(before) {
+			t.Fatalf("expected %d phrases in trash; got %#v", len(before), trash)
+		}
+
+		for _, p := range trash {
+			fatal(t, store.RestorePhrase(123, int(p.ID)))
+			checkConsistent(t)
+		}
+		after, err := store.GetAllPhrases(123)
+		fatal(t, err)
+		if !reflect.DeepEqual(before, after) {
+			t.Errorf("expected restored phrases to equal %#v; got %#v", before, after)
+		}
+		if trash, err := store.GetTrash(123); err != nil || len(trash) != 0 {
+			t.Errorf("expected empty trash; got %v, %v", trash, err)
+		}
+		if err := store.RestorePhrase(123, int(before[0].ID)); err != brain.ErrNotFound {
+			t.Errorf("expected ErrNotFound for phrase not in trash; got %v", err)
+		}
+	})
+
+	t.Run("purge", func(t *testing.T) {
+		seq := int(before[0].ID)
+		fatal(t, store.DeletePhrase(123, seq))
+		purged, err := store.PurgeTrash(time.Now().Add(-time.Hour))
+		fatal(t, err)
+		if purged != 0 {
+			t.Errorf("expected recently deleted phrase to be kept; purged %d", purged)
+		}
+		purged, err = store.PurgeTrash(time.Now().Add(time.Second))
+		fatal(t, err)
+		if purged != 1 {
+			t.Errorf("expected one purged phrase; got %d", purged)
+		}
+		if err := store.RestorePhrase(123, seq); err != brain.ErrNotFound {
+			t.Errorf("expected ErrNotFound for purged phrase; got %v", err)
+		}
+		checkConsistent(t)
+	})
+
+	t.Run("api", func(t *testing.T) {
+		apiToken, err := store.GenerateToken(123)
+		fatal(t, err)
+		errLogger := log.New(os.Stderr, "", log.LstdFlags|log.Llongfile)
+		mux := http.NewServeMux()
+		mux.Handle("/api/trash", http.StripPrefix("/api/trash", api.Trash(store, errLogger)))
+		mux.Handle("/api/trash/", http.StripPrefix("/api/trash/", api.Trash(store, errLogger)))
+		ts := httptest.NewServer(mux)
+		defer ts.Close()
+
+		seq := int(before[1].ID)
+		fatal(t, store.DeletePhrase(123, seq))
+
+		res, err := http.Get(ts.URL + "/api/trash?token=" + apiToken)
+		fatal(t, err)
+		var data struct {
+			Data []brain.TrashedPhrase `json:"data"`
+		}
+		fatal(t, json.NewDecoder(res.Body).Decode(&data))
+		fatal(t, res.Body.Close())
+		if len(data.Data) != 1 || data.Data[0].ID != int64(seq) || !data.Data[0].Suspended || data.Data[0].Deleted == 0 {
+			t.Errorf("expected deleted phrase in trash; got %#v", data.Data)
+		}
+
+		url := ts.URL + "/api/trash/" + strconv.Itoa(seq) + "/restore?token=" + apiToken
+		res, err = http.Post(url, "application/json", nil)
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("expected restore to succeed; got %d", res.StatusCode)
+		}
+		res, err = http.Post(url, "application/json", nil)
+		fatal(t, err)
+		fatal(t, res.Body.Close())
+		if res.StatusCode != http.StatusNotFound {
+			t.Errorf("expected restored phrase to be not found in trash; got %d", res.StatusCode)
+		}
+		checkConsistent(t)
+	})
+}
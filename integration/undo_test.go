@@ -0,0 +1,94 @@
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+)
+
+func TestUndo(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	yesterday := time.Now().Add(-24 * time.Hour)
+	fatal(t, store.AddPhrase(123, "phrase1", "explanation1", yesterday))
+	fatal(t, store.AddPhrase(123, "phrase2", "explanation2", yesterday))
+
+	if err := store.UndoLastStudy(123); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound without studies; got %v", err)
+	}
+
+	tt := []testCase{
+		{
+			name:     "get profile",
+			method:   "GET",
+			url:      "/123?fields=first_name,locale,timezone&access_token=some-test-token&appsecret_proof=e5565c0a91022866f93ae581ad8e3bddca01e06c067b5816f0373fc76df3d1f0",
+			response: `{ "first_name": "Chris", "locale": "en_US" }`,
+		},
+		{
+			name:   "correct",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"Correct!"}}`,
+		},
+		{
+			name:   "review 2",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"1. Do you know how to say this?\n\nexplanation2\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"},{"content_type":"text","title":"↩ undo","payload":"PAYLOAD_UNDO"}]}}`,
+			send:   fmt.Sprintf(formatPayload, "PAYLOAD_UNDO"),
+		},
+		{
+			name:   "review 1 again",
+			expect: `{"recipient":{"id":"123"},"message":{"text":"2. Do you know how to say this?\n\nexplanation1\n\nUse the buttons or type the phrase.","quick_replies":[{"content_type":"text","title":"done studying","payload":"PAYLOAD_STARTMENU"},{"content_type":"text","title":"👉 show phrase","payload":"PAYLOAD_SHOWSTUDY"},{"content_type":"text","title":"💡 hint","payload":"PAYLOAD_HINT"}]}}`,
+		},
+	}
+
+	state := 0
+	msg := make(chan string)
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc := tt[state]
+		checkCase(t, w, r, tc)
+		msg <- tc.send
+		state++
+		if state == len(tt) {
+			close(msg)
+		}
+	}))
+	defer ts.Close()
+
+	b, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+	})
+	fatal(t, err)
+
+	go send(t, b, fmt.Sprintf(formatMessage, "1", "phrase1"))
+
+	for s := range msg {
+		if s != "" {
+			go send(t, b, s)
+		}
+	}
+
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	for _, p := range phrases {
+		if p.Score != 0 {
+			t.Errorf("expected score to be reset by undo; got %#v", p)
+		}
+	}
+
+	// Only the last study can be undone
+	if err := store.UndoLastStudy(123); err != brain.ErrNotFound {
+		t.Errorf("expected ErrNotFound after undo; got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jorinvo/slangbrain/bot"
+	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/payload"
+)
+
+const formatUserMessage = `
+	{
+		"entry": [
+			{
+				"messaging": [
+					{
+						"sender": {
+							"id": "%d"
+						},
+						"timestamp": 0,
+						"message": {
+							"mid": "%s",
+							"text": "%s"
+						}
+					}
+				]
+			}
+		]
+	}
+`
+
+const formatUserRead = `
+	{
+		"entry": [
+			{
+				"messaging": [
+					{
+						"sender": {
+							"id": "%d"
+						},
+						"timestamp": 0,
+						"read": {
+							"watermark": %d
+						}
+					}
+				]
+			}
+		]
+	}
+`
+
+func TestUpdate(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+
+	t.Run("rollback", func(t *testing.T) {
+		err := store.Update(func(s brain.Store) error {
+			fatal(t, s.SetMode(1, brain.ModeAdd))
+			return errors.New("fail")
+		})
+		if err == nil {
+			t.Error("expected error")
+		}
+		mode, err := store.GetMode(1)
+		fatal(t, err)
+		if mode != brain.ModeGetStarted {
+			t.Errorf("expected mode to be rolled back; got %v", mode)
+		}
+	})
+
+	t.Run("failed write", func(t *testing.T) {
+		err := store.Update(func(s brain.Store) error {
+			fatal(t, s.SetMode(2, brain.ModeAdd))
+			// User has no phrases to study
+			if _, err := s.ScoreStudy(2, brain.GradeGood); err == nil {
+				t.Error("expected study to fail")
+			}
+			return nil
+		})
+		if err == nil {
+			t.Error("expected failed write to abort transaction")
+		}
+		mode, err := store.GetMode(2)
+		fatal(t, err)
+		if mode != brain.ModeGetStarted {
+			t.Errorf("expected mode to be rolled back; got %v", mode)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		var kept brain.Store
+		err := store.Update(func(s brain.Store) error {
+			kept = s
+			if err := s.SetMode(1, brain.ModeAdd); err != nil {
+				return err
+			}
+			mode, err := s.GetMode(1)
+			if err != nil {
+				return err
+			}
+			if mode != brain.ModeAdd {
+				t.Errorf("expected mode to be visible in transaction; got %v", mode)
+			}
+			return s.QueueMessage("1")
+		})
+		fatal(t, err)
+		mode, err := store.GetMode(1)
+		fatal(t, err)
+		if mode != brain.ModeAdd {
+			t.Errorf("expected mode to be saved; got %v", mode)
+		}
+		if err := store.QueueMessage("1"); err != brain.ErrExists {
+			t.Errorf("expected message to be queued; got %v", err)
+		}
+
+		// A store kept after the transaction uses the database again
+		fatal(t, kept.SetMode(1, brain.ModeStudy))
+		mode, err = store.GetMode(1)
+		fatal(t, err)
+		if mode != brain.ModeStudy {
+			t.Errorf("expected mode of kept store to be saved; got %v", mode)
+		}
+	})
+}
+
+func TestEventTx(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeAdd))
+
+	// Fake the Facebook server.
+	var sent int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{ "locale": "en_GB" }`)
+			return
+		}
+		atomic.AddInt32(&sent, 1)
+		// Messages are sent after the event is committed
+		if phrases, err := store.GetAllPhrases(123); err != nil || len(phrases) != 1 {
+			t.Errorf("expected phrase to be saved before sending; got %v, %v", phrases, err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- store.SetMode(456, brain.ModeAdd)
+		}()
+		select {
+		case err := <-done:
+			fatal(t, err)
+		case <-time.After(time.Second):
+			t.Error("expected database not to be locked while sending")
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	h, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+		EventTx:     true,
+	})
+	fatal(t, err)
+
+	send(t, h, fmt.Sprintf(formatUserMessage, 123, "1", `hola\nhello`))
+	if n := atomic.LoadInt32(&sent); n != 2 {
+		t.Errorf("expected two messages to be sent; got %d", n)
+	}
+}
+
+func TestEventTxUndo(t *testing.T) {
+	store, cleanup := initDB(t)
+	defer cleanup()
+	fatal(t, store.SetMode(123, brain.ModeStudy))
+	fatal(t, store.AddPhrase(123, "hola", "hello", time.Now().Add(-24*time.Hour)))
+	_, err := store.ScoreStudy(123, brain.GradeGood)
+	fatal(t, err)
+	phrases, err := store.GetAllPhrases(123)
+	fatal(t, err)
+	fatal(t, store.SuspendPhrase(123, int(phrases[0].ID), true))
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{ "locale": "en_GB" }`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	h, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+		EventTx:     true,
+	})
+	fatal(t, err)
+
+	// Undo of a suspended card fails and must not discard the undo
+	send(t, h, fmt.Sprintf(formatPayload, payload.Undo))
+	fatal(t, store.SuspendPhrase(123, int(phrases[0].ID), false))
+	fatal(t, store.UndoLastStudy(123))
+	phrases, err = store.GetAllPhrases(123)
+	fatal(t, err)
+	if phrases[0].Score != 0 {
+		t.Errorf("expected study to be undone; got %#v", phrases[0])
+	}
+}
+
+// BenchmarkWebhook measures throughput of many users adding phrases concurrently.
+// Each iteration handles a message and a read event of one user.
+// Run with -cpu to change the number of users.
+func BenchmarkWebhook(b *testing.B) {
+	for _, eventTx := range []bool{false, true} {
+		name := "update"
+		if eventTx {
+			name = "event tx"
+		}
+		b.Run(name, func(b *testing.B) {
+			benchmarkWebhook(b, eventTx)
+		})
+	}
+}
+
+func benchmarkWebhook(b *testing.B, eventTx bool) {
+	store, cleanup := initDB(b)
+	defer cleanup()
+
+	// Fake the Facebook server.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, `{ "locale": "en_GB" }`)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	h, _, err := bot.New(bot.Config{
+		Store:       store,
+		Token:       token,
+		Secret:      secret,
+		ErrLogger:   log.New(os.Stderr, "", log.LstdFlags|log.Llongfile),
+		FacebookURL: ts.URL,
+		EventTx:     eventTx,
+	})
+	fatal(b, err)
+
+	var users int64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		id := atomic.AddInt64(&users, 1)
+		fatal(b, store.SetMode(id, brain.ModeAdd))
+		for i := 0; pb.Next(); i++ {
+			mid := fmt.Sprintf("%d-%d", id, i)
+			send(b, h, fmt.Sprintf(formatUserMessage, id, mid, fmt.Sprintf(`phrase %d\nexplanation %d`, i, i)))
+			send(b, h, fmt.Sprintf(formatUserRead, id, i))
+		}
+	})
+}
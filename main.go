@@ -18,6 +18,7 @@ import (
 	"github.com/jorinvo/slangbrain/api"
 	"github.com/jorinvo/slangbrain/bot"
 	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/brain/kv"
 	"github.com/jorinvo/slangbrain/slack"
 	"github.com/jorinvo/slangbrain/translate"
 	"github.com/jorinvo/slangbrain/webview"
@@ -31,6 +32,7 @@ Usage: %s [flags]
 Slangbrain uses BoltDB as a database.
 Data is stored in a single file. No external system is needed.
 However, only one application can access the database at a time.
+Pending database migrations are applied on start. Pass -dryrun to only log them.
 
 Slangbrain starts a server to serve a webhook handler at /webhook that can be registered as a Messenger bot.
 If -http PORT is passed an HTTP-only server is started. Otherwise a production server is started with sockets activation via systemd,
@@ -52,6 +54,8 @@ const (
 	readTimeout  = 5 * time.Second
 	writeTimeout = 10 * time.Second
 	idleTimeout  = 120 * time.Second
+	// How often deleted phrases are checked for purging
+	purgeInterval = time.Hour
 )
 
 var version = "development"
@@ -74,6 +78,9 @@ func main() {
 		backupAuth  = flag.String("backupauth", "", "/backup basic auth in the form user:pasword. If empty, /backup is deactivated.")
 		domain      = flag.String("domain", "fbot.slangbrain.com", "Domain used for certs and internal links.")
 		noSetup     = flag.Bool("nosetup", false, "Skip sending setup instructions to Facebook")
+		dryRun      = flag.Bool("dryrun", false, "Log pending database migrations and exit without applying them.")
+		eventTx     = flag.Bool("eventtx", false, "Handle each webhook event in a single database transaction.")
+		retention   = flag.Duration("trashretention", 30*24*time.Hour, "Time deleted phrases can be restored before they are purged.")
 	)
 
 	// Parse and validate flags
@@ -88,6 +95,26 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *db == "" {
+		errorLogger.Println("Flag -db is required")
+		os.Exit(1)
+	}
+	if *dryRun {
+		kvDB, err := kv.OpenBolt(*db, 0600)
+		if err != nil {
+			errorLogger.Fatalln("failed to open database:", err)
+		}
+		from, to, err := brain.Migrate(kvDB, true, infoLogger)
+		if err != nil {
+			errorLogger.Fatalln("failed to migrate database:", err)
+		}
+		infoLogger.Printf("Dry run from version %d to %d", from, to)
+		if err := kvDB.Close(); err != nil {
+			errorLogger.Println(err)
+		}
+		os.Exit(0)
+	}
+
 	if *httpPort == 0 {
 		if *email == "" {
 			errorLogger.Fatalln("Flag -email is required")
@@ -97,10 +124,6 @@ func main() {
 		}
 	}
 
-	if *db == "" {
-		errorLogger.Println("Flag -db is required")
-		os.Exit(1)
-	}
 	if *token == "" {
 		errorLogger.Println("flag -token is required")
 		os.Exit(1)
@@ -119,7 +142,7 @@ func main() {
 	}
 
 	// Setup database
-	store, err := brain.New(*db)
+	store, err := brain.New(*db, brain.LogInfo(infoLogger))
 	if err != nil {
 		errorLogger.Fatalln("failed to create store:", err)
 	}
@@ -132,6 +155,20 @@ func main() {
 
 	translator := translate.New("https://" + *domain)
 
+	// Purge deleted phrases after retention
+	go func() {
+		for range time.Tick(purgeInterval) {
+			purged, err := store.PurgeTrash(time.Now().Add(-*retention))
+			if err != nil {
+				errorLogger.Println(err)
+				continue
+			}
+			if purged > 0 {
+				infoLogger.Printf("Purged %d deleted phrases", purged)
+			}
+		}
+	}()
+
 	// Listen to system events for graceful shutdown
 	shutdownSignals := make(chan os.Signal, 1)
 	signal.Notify(shutdownSignals, os.Interrupt)
@@ -150,6 +187,7 @@ func main() {
 		MessageDelay: 2 * time.Second,
 		Translator:   translator,
 		Setup:        !*noSetup,
+		EventTx:      *eventTx,
 	})
 	if err != nil {
 		errorLogger.Fatalln("failed to start bot:", err)
@@ -180,14 +218,23 @@ func main() {
 
 	apiHandler := api.Phrases(store, errorLogger)
 	csvHandler := api.CSV(store, errorLogger)
+	settingsAPIHandler := api.Settings(store, errorLogger)
+	forecastAPIHandler := api.Forecast(store, errorLogger)
+	trashHandler := api.Trash(store, errorLogger)
 	webviewHandler := webview.New(store, errorLogger, translator, "/api/")
+	settingsHandler := webview.NewSettings(store, errorLogger, translator, "/api/")
 
 	mux := http.NewServeMux()
 	mux.Handle("/webhook", webhookHandler)
 	mux.Handle("/api/phrases.csv", csvHandler)
 	mux.Handle("/api/phrases", http.StripPrefix("/api/phrases", apiHandler))
 	mux.Handle("/api/phrases/", http.StripPrefix("/api/phrases/", apiHandler))
+	mux.Handle("/api/settings", settingsAPIHandler)
+	mux.Handle("/api/forecast", forecastAPIHandler)
+	mux.Handle("/api/trash", http.StripPrefix("/api/trash", trashHandler))
+	mux.Handle("/api/trash/", http.StripPrefix("/api/trash/", trashHandler))
 	mux.Handle("/webview/manage/", http.StripPrefix("/webview/manage/", webviewHandler))
+	mux.Handle("/webview/settings/", http.StripPrefix("/webview/settings/", settingsHandler))
 	mux.Handle("/slack", slackHandler)
 	mux.Handle("/status", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintln(w, "OK")
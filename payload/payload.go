@@ -1,5 +1,12 @@
 package payload
 
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 const (
 	Idle          = "PAYLOAD_IDLE"
 	Help          = "PAYLOAD_SHOWHELP"
@@ -8,9 +15,10 @@ const (
 	Study         = "PAYLOAD_STARTSTUDY"
 	GetStarted    = "PAYLOAD_GETSTARTED"
 	ShowPhrase    = "PAYLOAD_SHOWSTUDY"
-	ScoreBad      = "PAYLOAD_SCOREBAD"
-	ScoreOk       = "PAYLOAD_SCOREOK"
+	ScoreAgain    = "PAYLOAD_SCOREAGAIN"
+	ScoreHard     = "PAYLOAD_SCOREHARD"
 	ScoreGood     = "PAYLOAD_SCOREGOOD"
+	ScoreEasy     = "PAYLOAD_SCOREEASY"
 	Subscribe     = "PAYLOAD_SUBSCRIBE"
 	Unsubscribe   = "PAYLOAD_UNSUBSCRIBE"
 	DenySubscribe = "PAYLOAD_NOSUBSCRIPTION"
@@ -19,4 +27,48 @@ const (
 	GetToken      = "PAYLOAD_GETTOKEN"
 	ConfirmImport = "PAYLOAD_CONFIRMIMPORT"
 	CancelImport  = "PAYLOAD_CANCELIMPORT"
+	Tags          = "PAYLOAD_SHOWTAGS"
+	Undo          = "PAYLOAD_UNDO"
+	Hint          = "PAYLOAD_HINT"
+	Continue      = "PAYLOAD_CONTINUESTUDY"
+	Forecast      = "PAYLOAD_FORECAST"
+	// StudyTag is a prefix, the tag to study is appended to it.
+	StudyTag = "PAYLOAD_STUDYTAG:"
+	// Suspend is a prefix, the ID of the phrase to suspend is appended to it.
+	Suspend = "PAYLOAD_SUSPEND:"
+	// Bury is a prefix, the ID of the phrase to bury is appended to it.
+	Bury = "PAYLOAD_BURY:"
+	// Choice is a prefix, use EncodeChoice and DecodeChoice for the rest of the payload.
+	Choice = "PAYLOAD_CHOICE:"
+
+	// ScoreBad and ScoreOk are only handled for replies sent before grades have been introduced.
+	ScoreBad = "PAYLOAD_SCOREBAD"
+	ScoreOk  = "PAYLOAD_SCOREOK"
 )
+
+// EncodeChoice creates the payload for the option with the given index of a multiple choice study.
+// The card is included to detect options of studies that are already done.
+// Only IDs are used to keep the payload short, no matter how long phrases are.
+func EncodeChoice(card int64, option int) string {
+	return Choice + strconv.FormatInt(card, 10) + ":" + strconv.Itoa(option)
+}
+
+// DecodeChoice returns card and option index of a payload created by EncodeChoice.
+func DecodeChoice(p string) (int64, int, error) {
+	if !strings.HasPrefix(p, Choice) {
+		return 0, 0, errors.New("not a choice payload")
+	}
+	parts := strings.Split(strings.TrimPrefix(p, Choice), ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid choice payload '%s'", p)
+	}
+	card, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	option, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return card, option, nil
+}
@@ -10,6 +10,8 @@ import (
 // They are already localized for one language.
 type Btn struct {
 	Help func(string) []fbot.Button
+	// Leech links to the phrase manager to edit a leech.
+	Leech func(string) []fbot.Button
 }
 
 func newBtn(l labels, serverURL string) Btn {
@@ -30,5 +32,11 @@ func newBtn(l labels, serverURL string) Btn {
 				homepage,
 			}
 		},
+		Leech: func(token string) []fbot.Button {
+			if serverURL == "" || token == "" {
+				return nil
+			}
+			return []fbot.Button{fbot.URLButton(l.Manage, manager+token)}
+		},
 	}
 }
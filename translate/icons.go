@@ -11,4 +11,8 @@ const (
 	iconBad      = "\U0001F44E"
 	iconOK       = "\U0001F914"
 	iconThumbsup = "\U0001F44D"
+	iconEasy     = "\U0001F4AF"
+	iconTag      = "\U0001F3F7"
+	iconUndo     = "\u21A9"
+	iconHint     = "\U0001F4A1"
 )
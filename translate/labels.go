@@ -5,6 +5,7 @@ package translate
 type labels struct {
 	StudyDone,
 	Study,
+	Tags,
 	Add,
 	Done,
 	Help,
@@ -17,12 +18,20 @@ type labels struct {
 	CancelFeedback,
 	StopAdding,
 	ShowPhrase,
-	ScoreBad,
+	Hint,
+	ScoreAgain,
+	ScoreHard,
 	ScoreGood,
+	ScoreEasy,
+	Undo,
+	Suspend,
+	Bury,
+	Continue,
 	StudyNotNow,
 	Manage,
 	ImportHelp,
 	GetToken,
+	Forecast,
 	Export,
 	CloseImportHelp,
 	ConfirmImport,
@@ -29,8 +29,14 @@ Lass uns damit anfangen die Vokabeln zu wiederholen.`,
 		StudyDone: `Glückwunsch, du hast alle Vokabeln fürs Erste geschafft!
 In %s gibt es wieder etwas zu wiederholen.`,
 		StudyCorrect: "Richtig!",
+		StudyClose: `Fast richtig! Das ist anders:
+
+%s`,
 		StudyWrong: `Richtig heißt es:
 
+%s`,
+		Hint: `Tipp:
+
 %s`,
 		StudyEmpty: `Du hast noch keine Vokabeln hinzugefügt.
 Klicke den Button um anzufangen:`,
@@ -39,6 +45,23 @@ Klicke den Button um anzufangen:`,
 %s
 
 Schicke mir die Antwort oder klicke den Button.`,
+		StudyQuestionReverse: `%d. Weißt du was das bedeutet?
+
+%s
+
+Schicke mir die Antwort oder klicke den Button.`,
+		StudyQuestionCloze: `%d. Weißt du was fehlt?
+
+%s
+
+Schicke mir die Antwort oder klicke den Button.`,
+		Tags:      "Welchen Tag willst du lernen?",
+		TagsEmpty: "Du hast noch keine Vokabeln mit Tags versehen. Im Hilfe-Menü kannst du deine Vokabeln bearbeiten und Tags hinzufügen.",
+		UndoEmpty: "Es gibt keine Antwort, die du rückgängig machen kannst.",
+		Leech: `Du vergisst diese Vokabel immer wieder.
+Vielleicht hilft es, sie etwas zu ändern. Du kannst sie auch pausieren oder morgen wiederholen.`,
+		Suspended: "Die Vokabel wird nicht mehr abgefragt. Du kannst sie wieder aktivieren, wenn du deine Vokabeln bearbeitest.",
+		Buried:    "Die Vokabel wird morgen wieder abgefragt.",
 		ExplanationExists: `Du hast schon eine Vokabel mit der gleichen Erklärung:
 %s
 %s
@@ -51,6 +74,10 @@ Mit Erklärung:
 %s`,
 		AddNext:            "Schicke die nächste Vokabel.",
 		StudyNotification:  `Hey %s, %d Vokabeln warten auf dich!`,
+		GoalNudge:          `Hey %s, du hast dein Tagesziel noch nicht erreicht. %s`,
+		GoalProgress:       "Heute: %s",
+		GoalStudies:        "%d/%d wiederholt",
+		GoalAdds:           "%d/%d hinzugefügt",
 		AskToSubscribe:     `Soll ich dir eine Nachricht schicken sobald es Vokabeln zu wiederholen gibt?`,
 		Subscribed:         `Ich schicke dir eine Nachricht sobald es etwas zu wiederholen gibt.`,
 		ConfirmUnsubscribe: `Alles klar, du bekommst in Zukunft keine Benachrichtigungen mehr.`,
@@ -59,6 +86,9 @@ Mit Erklärung:
 		FeedbackDone:       "Danke %s, wir melden uns bei dir sobald wie möglich.",
 		ImportHelp1: `Du kannst viele Vokabeln auf einmal hinzufügen indem du Slangbrain eine CSV Datei schickst.
 Die Datei muss die Endung '.csv' haben und sie muss 2 Spalten haben, wobei die erste Spalte für Vokabeln und die zweite für deren Erklärungen ist.
+Weitere richtige Antworten können in eine optionale dritte Spalte geschrieben werden, eine Antwort pro Zeile.
+Um nur nach einem Teil eines Satzes gefragt zu werden, markiere den Teil so: {{c1::dieser}}. Markiere weitere Teile mit c2, c3 usw. um sie getrennt zu lernen.
+Optionale Spalten nach der dritten können Tags für die Vokabel enthalten, ein Tag pro Spalte.
 Stelle sicher, dass die CSV Datei keine Kopfzeile, also keine Spaltentitel, enthält. Die Spalten werden durch ein Komma voneinander getrennt. Jeden Zelle kann in Anführungszeichen gesetzt werden, was hilfreich ist, falls man in der Zelle ein Komma benutzen will.
 Eine CSV Datei kann z.B. so aussehen:`,
 		ImportHelp2: `Bonjour !,Hallo!
@@ -73,6 +103,18 @@ Eine CSV Datei kann z.B. so aussehen:`,
 '%v'`,
 		ImportErrCols: "Die CSV Dateien muss mindestens 2 Spalten haben, aber die Datei '%s' hat %d Spalten. Die erste Spalte ist für Vokabeln und die zweite für deren Erklärungen.",
 		WeeklyStats:   "Diese Woche hast du %s hinzugefügt und %d wiederholt. Du hast jetzt insgesamt %d Punkte und bist auf Platz %d von allen Slangbrain Nutzern.",
+		SessionSummary: `Wiederholt: %d
+Richtig: %d%%
+Gelernt: %d`,
+		SessionHardest: "Am schwersten: %s",
+		Streak:         "Lernserie: %s",
+		Forecast: `Anstehende Wiederholungen:
+
+%s`,
+		ForecastDay: "%s: %d",
+		ForecastNew: " (+%d neu)",
+		Today:       "heute",
+		DateFormat:  "2.1.",
 		APIToken: `Hier ist dein API Token:
 
 %s
@@ -80,6 +122,8 @@ Eine CSV Datei kann z.B. so aussehen:`,
 Pass' gut darauf auf!`,
 		Phrase:  "Vokabel",
 		Phrases: "Vokabeln",
+		Day:     "Tag",
+		Days:    "Tage",
 		AnHour:  "einer Stunde",
 		Hours:   "Stunden",
 		AMinute: "einer Minute",
@@ -90,11 +134,13 @@ Pass' gut darauf auf!`,
 	l := labels{
 		StudyDone:            "genug gelernt",
 		Study:                "lernen",
+		Tags:                 "Tags",
 		Add:                  "neu",
 		Done:                 "fertig",
 		Help:                 "Hilfe",
 		ImportHelp:           "Vokabeln importieren",
 		GetToken:             "API Token",
+		Forecast:             "Vorschau",
 		Export:               "Vokabeln herunterladen",
 		CloseImportHelp:      "ok",
 		SubscribeConfirm:     "gerne",
@@ -106,8 +152,15 @@ Pass' gut darauf auf!`,
 		CancelFeedback:       "abbrechen",
 		StopAdding:           "stop",
 		ShowPhrase:           "zeigen",
-		ScoreBad:             "weiß nicht",
-		ScoreGood:            "weiß ich",
+		Hint:                 "Tipp",
+		ScoreAgain:           "nochmal",
+		ScoreHard:            "schwer",
+		ScoreGood:            "gut",
+		ScoreEasy:            "leicht",
+		Undo:                 "rückgängig",
+		Suspend:              "pausieren",
+		Bury:                 "morgen",
+		Continue:             "weiterlernen",
 		StudyNotNow:          "nicht jetzt",
 		Manage:               "Vokabeln bearbeiten",
 		ConfirmImport:        "ja",
@@ -117,19 +170,51 @@ Pass' gut darauf auf!`,
 	}
 
 	w := Web{
-		Title:         "Vokabeln bearbeiten",
-		Search:        "Suchen",
-		Empty:         "Keine Vokabeln gefunden.",
-		Phrases:       "Vokabeln insgesamt",
-		Phrase:        "Vokabel",
-		Explanation:   "Erklärung",
-		Delete:        "Löschen",
-		Cancel:        "Abbrechen",
-		DeleteConfirm: "Wirklich löschen",
-		Save:          "Speichern",
-		Error:         "Leider ist etwas schief gelaufen. Versuche es bitte noch einmal.",
-		Updated:       "Vokabel aktualisiert",
-		Deleted:       "Vokabel gelöscht",
+		Title:              "Vokabeln bearbeiten",
+		Search:             "Suchen",
+		Empty:              "Keine Vokabeln gefunden.",
+		Phrases:            "Vokabeln insgesamt",
+		Phrase:             "Vokabel",
+		Explanation:        "Erklärung",
+		Alternatives:       "Weitere Antworten, getrennt durch |",
+		Tags:               "Tags, getrennt durch Kommas",
+		Forward:            "nach Vokabel fragen",
+		Reverse:            "nach Erklärung fragen",
+		Both:               "in beide Richtungen fragen",
+		Active:             "weiter lernen",
+		Suspended:          "pausiert",
+		Delete:             "Löschen",
+		Cancel:             "Abbrechen",
+		DeleteConfirm:      "Wirklich löschen",
+		Save:               "Speichern",
+		Error:              "Leider ist etwas schief gelaufen. Versuche es bitte noch einmal.",
+		Updated:            "Vokabel aktualisiert",
+		Deleted:            "Vokabel gelöscht",
+		More:               "weitere Vokabeln",
+		Restore:            "Wiederherstellen",
+		Trash:              "Gelöschte Vokabeln",
+		TrashEmpty:         "Keine gelöschten Vokabeln.",
+		Reviews:            "Wiederholungen",
+		Lapses:             "Vergessen",
+		SuccessRate:        "Gewusst",
+		AverageInterval:    "Tage zwischen Wiederholungen",
+		Settings:           "Einstellungen",
+		NewPhrases:         "Neue Vokabeln gleichzeitig",
+		IntervalMultiplier: "Lernabstände multiplizieren mit",
+		NotifyMinCount:     "Fällige Vokabeln vor einer Benachrichtigung",
+		QuietStart:         "Keine Benachrichtigungen von",
+		QuietEnd:           "bis",
+		StudyBy:            "Lernen durch",
+		TypeAnswers:        "Antwort eintippen",
+		MultipleChoice:     "Antwort auswählen",
+		DailyStudies:       "Tagesziel für wiederholte Vokabeln",
+		DailyAdds:          "Tagesziel für neue Vokabeln",
+		Scheduler:          "Wiederholungen planen mit",
+		SchedulerTable:     "festen Abständen",
+		SchedulerSM2:       "SM-2",
+		SchedulerFSRS:      "FSRS",
+		Forecast:           "Anstehende Wiederholungen",
+		SettingsUpdated:    "Einstellungen gespeichert",
 	}
 
 	return m, l, w
@@ -33,8 +33,14 @@ Let's start with memorizing them.`,
 		StudyDone: `Congrats, you finished all your studies for now!
 Come back in %s.`,
 		StudyCorrect: "Correct!",
+		StudyClose: `Almost correct! Here is what's different:
+
+%s`,
 		StudyWrong: `Sorry, the right version is:
 
+%s`,
+		Hint: `Hint:
+
 %s`,
 		StudyEmpty: `You have added no phrases yet.
 Click the button below and get started.`,
@@ -43,6 +49,23 @@ Click the button below and get started.`,
 %s
 
 Use the buttons or type the phrase.`,
+		StudyQuestionReverse: `%d. Do you know what this means?
+
+%s
+
+Use the buttons or type the explanation.`,
+		StudyQuestionCloze: `%d. Do you know what is missing?
+
+%s
+
+Use the buttons or type the missing part.`,
+		Tags:      "Which tag would you like to study?",
+		TagsEmpty: "You haven't tagged any phrases yet. You can add tags to your phrases when you manage them from the help menu.",
+		UndoEmpty: "There is no answer to undo.",
+		Leech: `You keep forgetting this phrase.
+Maybe it helps to change it a bit. You can also suspend it or study it again tomorrow.`,
+		Suspended: "The phrase won't be studied anymore. You can unsuspend it when you manage your phrases.",
+		Buried:    "The phrase will be studied again tomorrow.",
 		ExplanationExists: `You already saved a phrase with the same explanation:
 %s
 %s
@@ -55,6 +78,10 @@ With explanation:
 %s`,
 		AddNext:            "Add next phrase.",
 		StudyNotification:  `Hey %s, you have %d phrases ready for review!`,
+		GoalNudge:          `Hey %s, you haven't reached your daily goal yet. %s`,
+		GoalProgress:       "Today: %s",
+		GoalStudies:        "%d/%d studied",
+		GoalAdds:           "%d/%d added",
 		AskToSubscribe:     `Would you like me to send you a message when there are phrases ready for studying?`,
 		Subscribed:         `Good, I will send you a message when your phrases are ready.`,
 		ConfirmUnsubscribe: `Sure, you won't receive any more notifications.`,
@@ -63,6 +90,9 @@ With explanation:
 		FeedbackDone:       "Thanks %s, you will hear from us soon.",
 		ImportHelp1: `You can add many phrases at once by sending a CSV file to Slangbrain.
 The file needs to end with '.csv' and it needs to have 2 columns, the first one is for  phrases, the second for their explanations.
+Other accepted answers can be added in an optional third column, one answer per line.
+To only be asked for a part of a sentence, mark the part like {{c1::this}}. Mark more parts with c2, c3 and so on to study them separately.
+Optional columns after the third one can contain tags for the phrase, one tag per column.
 Don't add any header row in the CSV file. The columns on each line need to be separated by a comma. Each cell can be wrapped in quotes which is helpful if a cell contains a comma.
 A valid file could look like this:`,
 		ImportHelp2: `hola,hello
@@ -77,6 +107,18 @@ A valid file could look like this:`,
 '%v'`,
 		ImportErrCols: "Expecting CSV files to have at least 2 columns, but file '%s' has %d. The first one should contain the phrase, the second an explanation.",
 		WeeklyStats:   "This week you added %s and studied %d. Your total score is %d now and you are #%d of all Slangbrain users.",
+		SessionSummary: `Studied: %d
+Correct: %d%%
+Learned: %d`,
+		SessionHardest: "Hardest: %s",
+		Streak:         "Streak: %s",
+		Forecast: `Reviews coming up:
+
+%s`,
+		ForecastDay: "%s: %d",
+		ForecastNew: " (+%d new)",
+		Today:       "today",
+		DateFormat:  "Jan 2",
 		APIToken: `Here is your API token:
 
 %s
@@ -84,6 +126,8 @@ A valid file could look like this:`,
 Keep it secret!`,
 		Phrase:  "phrase",
 		Phrases: "phrases",
+		Day:     "day",
+		Days:    "days",
 		AnHour:  "an hour",
 		Hours:   "hours",
 		AMinute: "a minute",
@@ -94,11 +138,13 @@ Keep it secret!`,
 	l := labels{
 		StudyDone:            "done studying",
 		Study:                "study",
+		Tags:                 "tags",
 		Add:                  "phrases",
 		Done:                 "done",
 		Help:                 "help",
 		ImportHelp:           "import phrases",
 		GetToken:             "get API token",
+		Forecast:             "forecast",
 		Export:               "download phrases",
 		CloseImportHelp:      "ok",
 		SubscribeConfirm:     "sounds good",
@@ -110,8 +156,15 @@ Keep it secret!`,
 		CancelFeedback:       "cancel",
 		StopAdding:           "stop adding",
 		ShowPhrase:           "show phrase",
-		ScoreBad:             "didn't know",
-		ScoreGood:            "got it",
+		Hint:                 "hint",
+		ScoreAgain:           "again",
+		ScoreHard:            "hard",
+		ScoreGood:            "good",
+		ScoreEasy:            "easy",
+		Undo:                 "undo",
+		Suspend:              "suspend",
+		Bury:                 "tomorrow",
+		Continue:             "keep studying",
 		StudyNotNow:          "not now",
 		Manage:               "manage phrases",
 		ConfirmImport:        "yes",
@@ -121,19 +174,51 @@ Keep it secret!`,
 	}
 
 	w := Web{
-		Title:         "Manage phrases",
-		Search:        "Search",
-		Empty:         "No phrases found.",
-		Phrases:       "phrases in total",
-		Phrase:        "Phrase",
-		Explanation:   "Explanation",
-		Delete:        "delete",
-		Cancel:        "cancel",
-		DeleteConfirm: "confirm delete",
-		Save:          "save",
-		Error:         "Something went wrong. Please try again.",
-		Updated:       "updated phrase",
-		Deleted:       "deleted phrase",
+		Title:              "Manage phrases",
+		Search:             "Search",
+		Empty:              "No phrases found.",
+		Phrases:            "phrases in total",
+		Phrase:             "Phrase",
+		Explanation:        "Explanation",
+		Alternatives:       "Other answers, separated by |",
+		Tags:               "Tags, separated by commas",
+		Forward:            "ask for phrase",
+		Reverse:            "ask for explanation",
+		Both:               "ask both ways",
+		Active:             "keep studying",
+		Suspended:          "suspended",
+		Delete:             "delete",
+		Cancel:             "cancel",
+		DeleteConfirm:      "confirm delete",
+		Save:               "save",
+		Error:              "Something went wrong. Please try again.",
+		Updated:            "updated phrase",
+		Deleted:            "deleted phrase",
+		More:               "more phrases",
+		Restore:            "restore",
+		Trash:              "Deleted phrases",
+		TrashEmpty:         "No deleted phrases.",
+		Reviews:            "Reviews",
+		Lapses:             "Forgotten",
+		SuccessRate:        "Known",
+		AverageInterval:    "Days between reviews",
+		Settings:           "Settings",
+		NewPhrases:         "New phrases studied at a time",
+		IntervalMultiplier: "Multiply study intervals by",
+		NotifyMinCount:     "Phrases due before notifying you",
+		QuietStart:         "No notifications from",
+		QuietEnd:           "until",
+		StudyBy:            "Study by",
+		TypeAnswers:        "typing the answer",
+		MultipleChoice:     "picking from options",
+		DailyStudies:       "Daily goal for studied phrases",
+		DailyAdds:          "Daily goal for new phrases",
+		Scheduler:          "Schedule reviews with",
+		SchedulerTable:     "fixed intervals",
+		SchedulerSM2:       "SM-2",
+		SchedulerFSRS:      "FSRS",
+		Forecast:           "Reviews coming up",
+		SettingsUpdated:    "updated settings",
 	}
 
 	return m, l, w
@@ -18,13 +18,27 @@ type Msg struct {
 	PhraseMissing,
 	StudyDone,
 	StudyCorrect,
+	StudyClose,
 	StudyWrong,
+	Hint,
 	StudyEmpty,
 	StudyQuestion,
+	StudyQuestionReverse,
+	StudyQuestionCloze,
+	Tags,
+	TagsEmpty,
+	UndoEmpty,
+	Leech,
+	Suspended,
+	Buried,
 	ExplanationExists,
 	AddDone,
 	AddNext,
 	StudyNotification,
+	GoalNudge,
+	GoalProgress,
+	GoalStudies,
+	GoalAdds,
 	AskToSubscribe,
 	Subscribed,
 	ConfirmUnsubscribe,
@@ -42,9 +56,19 @@ type Msg struct {
 	ImportErrParse,
 	ImportErrCols,
 	WeeklyStats,
+	SessionSummary,
+	SessionHardest,
+	Streak,
+	Forecast,
+	ForecastDay,
+	ForecastNew,
+	Today,
+	DateFormat,
 	APIToken,
 	Phrase,
 	Phrases,
+	Day,
+	Days,
 	AnHour,
 	Hours,
 	AMinute,
@@ -1,6 +1,8 @@
 package translate
 
 import (
+	"strconv"
+
 	"github.com/jorinvo/slangbrain/payload"
 	"qvl.io/fbot"
 )
@@ -16,18 +18,30 @@ type Rpl struct {
 	AddMode,
 	StudyMode,
 	Show,
+	ShowUndo,
 	Score,
 	StudyEmpty,
 	StudiesDue,
 	ConfirmDelete,
 	ImportHelp,
 	Import []fbot.Reply
+	// StudyTags lets the user choose one of the passed tags to study.
+	StudyTags func([]string) []fbot.Reply
+	// Leech lets the user suspend or bury the phrase with the passed ID.
+	Leech func(int64) []fbot.Reply
+	// Choices lets the user pick one of the options as answer for the card with the passed ID.
+	// If undo is set, the user can also undo the previous study.
+	Choices func(card int64, options []string, undo bool) []fbot.Reply
 }
 
 func newRpl(l labels) Rpl {
 	var (
 		studyDone  = fbot.Reply{Text: l.StudyDone, Payload: payload.Menu}
+		show       = fbot.Reply{Text: iconShow + " " + l.ShowPhrase, Payload: payload.ShowPhrase}
+		hint       = fbot.Reply{Text: iconHint + " " + l.Hint, Payload: payload.Hint}
+		undo       = fbot.Reply{Text: iconUndo + " " + l.Undo, Payload: payload.Undo}
 		study      = fbot.Reply{Text: iconStudy + " " + l.Study, Payload: payload.Study}
+		tags       = fbot.Reply{Text: iconTag + " " + l.Tags, Payload: payload.Tags}
 		add        = fbot.Reply{Text: iconAdd + " " + l.Add, Payload: payload.Add}
 		done       = fbot.Reply{Text: iconDone + " " + l.Done, Payload: payload.Idle}
 		help       = fbot.Reply{Text: iconHelp + " " + l.Help, Payload: payload.Help}
@@ -35,11 +49,13 @@ func newRpl(l labels) Rpl {
 		quitHelp   = fbot.Reply{Text: l.QuitHelp, Payload: payload.Menu}
 		feedback   = fbot.Reply{Text: l.SendFeedback, Payload: payload.Feedback}
 		getToken   = fbot.Reply{Text: l.GetToken, Payload: payload.GetToken}
+		forecast   = fbot.Reply{Text: l.Forecast, Payload: payload.Forecast}
 	)
 
 	return Rpl{
 		MenuMode: []fbot.Reply{
 			study,
+			tags,
 			add,
 			help,
 			done,
@@ -54,6 +70,7 @@ func newRpl(l labels) Rpl {
 			feedback,
 			importHelp,
 			getToken,
+			forecast,
 		},
 		HelpUnsubscribe: []fbot.Reply{
 			quitHelp,
@@ -61,6 +78,7 @@ func newRpl(l labels) Rpl {
 			feedback,
 			importHelp,
 			getToken,
+			forecast,
 		},
 		Feedback: []fbot.Reply{
 			fbot.Reply{Text: iconDelete + " " + l.CancelFeedback, Payload: payload.Menu},
@@ -73,12 +91,20 @@ func newRpl(l labels) Rpl {
 		},
 		Show: []fbot.Reply{
 			studyDone,
-			fbot.Reply{Text: iconShow + " " + l.ShowPhrase, Payload: payload.ShowPhrase},
+			show,
+			hint,
+		},
+		ShowUndo: []fbot.Reply{
+			studyDone,
+			show,
+			hint,
+			undo,
 		},
 		Score: []fbot.Reply{
-			fbot.Reply{Text: iconBad + " " + l.ScoreBad, Payload: payload.ScoreBad},
-			fbot.Reply{Text: iconOK, Payload: payload.ScoreOk},
+			fbot.Reply{Text: iconBad + " " + l.ScoreAgain, Payload: payload.ScoreAgain},
+			fbot.Reply{Text: iconOK + " " + l.ScoreHard, Payload: payload.ScoreHard},
 			fbot.Reply{Text: iconGood + " " + l.ScoreGood, Payload: payload.ScoreGood},
+			fbot.Reply{Text: iconEasy + " " + l.ScoreEasy, Payload: payload.ScoreEasy},
 		},
 		StudyEmpty: []fbot.Reply{
 			add,
@@ -94,5 +120,43 @@ func newRpl(l labels) Rpl {
 			fbot.Reply{Text: iconGood + " " + l.ConfirmImport, Payload: payload.ConfirmImport},
 			fbot.Reply{Text: l.CancelImport, Payload: payload.CancelImport},
 		},
+		StudyTags: func(tags []string) []fbot.Reply {
+			replies := []fbot.Reply{quitHelp}
+			for _, tag := range tags {
+				// Messenger allows at most 11 quick replies
+				if len(replies) == 11 {
+					break
+				}
+				replies = append(replies, fbot.Reply{Text: tag, Payload: payload.StudyTag + tag})
+			}
+			return replies
+		},
+		Leech: func(id int64) []fbot.Reply {
+			seq := strconv.FormatInt(id, 10)
+			return []fbot.Reply{
+				fbot.Reply{Text: l.Continue, Payload: payload.Continue},
+				fbot.Reply{Text: l.Suspend, Payload: payload.Suspend + seq},
+				fbot.Reply{Text: l.Bury, Payload: payload.Bury + seq},
+			}
+		},
+		Choices: func(card int64, options []string, withUndo bool) []fbot.Reply {
+			replies := []fbot.Reply{studyDone}
+			for i, o := range options {
+				replies = append(replies, fbot.Reply{Text: truncate(o, 20), Payload: payload.EncodeChoice(card, i)})
+			}
+			if withUndo {
+				replies = append(replies, undo)
+			}
+			return replies
+		},
+	}
+}
+
+// Shorten s to at most n chars.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
 	}
+	return string(r[:n-1]) + "\u2026"
 }
@@ -8,11 +8,43 @@ type Web struct {
 	Phrases,
 	Phrase,
 	Explanation,
+	Alternatives,
+	Tags,
+	Forward,
+	Reverse,
+	Both,
+	Active,
+	Suspended,
 	Delete,
 	Cancel,
 	DeleteConfirm,
 	Save,
 	Error,
 	Updated,
-	Deleted string
+	Deleted,
+	More,
+	Restore,
+	Trash,
+	TrashEmpty,
+	Reviews,
+	Lapses,
+	SuccessRate,
+	AverageInterval,
+	Settings,
+	NewPhrases,
+	IntervalMultiplier,
+	NotifyMinCount,
+	QuietStart,
+	QuietEnd,
+	StudyBy,
+	TypeAnswers,
+	MultipleChoice,
+	DailyStudies,
+	DailyAdds,
+	Scheduler,
+	SchedulerTable,
+	SchedulerSM2,
+	SchedulerFSRS,
+	Forecast,
+	SettingsUpdated string
 }
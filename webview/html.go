@@ -31,6 +31,7 @@ const html = `<!DOCTYPE html>
 				display: none;
 			}
 			input,
+			select,
 			textarea {
 				width: 94%;
 				padding: 2%;
@@ -80,9 +81,48 @@ const html = `<!DOCTYPE html>
 			.phrase span:first-child {
 				font-weight: bold;
 			}
+			.phrase .tags {
+				font-size: 86%;
+				color: #939393;
+			}
+			.phrase.suspended {
+				opacity: 0.5;
+			}
+			.phrase.leech {
+				border-left: 3px solid #ff207e;
+			}
+			.settings {
+				display: block;
+				margin: 5% 0 10%;
+				font-size: 86%;
+				text-align: center;
+				color: #ff207e;
+			}
 			.open {
 				background: rgba(255, 32, 126, 0.5);
 			}
+			.more {
+				display: block;
+				margin: 10% 0;
+				text-align: center;
+				color: #ff207e;
+			}
+			.trash {
+				margin: 0 0 10%;
+			}
+			.trash .phrase {
+				cursor: default;
+				opacity: 0.5;
+			}
+			.trash .restore {
+				color: #ff207e;
+				cursor: pointer;
+			}
+			.update .restore {
+				margin-left: 3%;
+				color: white;
+				text-decoration: underline;
+			}
 			.total {
 				margin: 10% 0;
 				font-size: 86%;
@@ -99,6 +139,18 @@ const html = `<!DOCTYPE html>
 				margin: 1.5%;
 				background: white;
 			}
+			.history {
+				margin: 1.5%;
+				font-size: 86%;
+				color: #939393;
+			}
+			.history ol {
+				margin: 2% 0 0;
+				padding-left: 5%;
+			}
+			.history .failed {
+				color: #ff207e;
+			}
 			.delete-prompt {
 				position: absolute;
 				bottom: 0;
@@ -139,6 +191,7 @@ const html = `<!DOCTYPE html>
 				background-color: rgba(255, 32, 126, 0.1);
 			}
 			input:focus,
+			select:focus,
 			textarea:focus {
 				outline: none;
 				border: 1px solid #939393;
@@ -146,6 +199,7 @@ const html = `<!DOCTYPE html>
 			button,
 			textarea,
 			input,
+			select,
 			.phrase {
 				border-radius: 5px;
 			}
@@ -158,13 +212,30 @@ const html = `<!DOCTYPE html>
 				<input id="search" class="search" type="search" placeholder="{{.Label.Search}}">
 				<div id="empty" class="empty hide">{{.Label.Empty}}</div>
 				<ul id="phrases" class="phrases"></ul>
-				{{if len .Phrases | lt 5}}
+				{{if .Next}}
+				<a class="more" href="{{.Next}}">{{.Label.More}}</a>
+				{{else if len .Phrases | lt 5}}
 				<div class="total">{{len .Phrases}} {{.Label.Phrases}}</div>
 				{{end}}
+				<a class="settings" href="../settings/{{.Token}}">{{.Label.Settings}}</a>
+				<a id="trash-open" class="settings" href="#">{{.Label.Trash}}</a>
+				<ul id="trash" class="phrases trash hide"></ul>
 			</div>
 			<div id="edit" class="edit hide">
 				<input id="edit-phrase" type="text" placeholder="{{.Label.Phrase}}">
+				<input id="edit-alternatives" type="text" placeholder="{{.Label.Alternatives}}">
 				<textarea id="edit-explanation" placeholder="{{.Label.Explanation}}"></textarea>
+				<input id="edit-tags" type="text" placeholder="{{.Label.Tags}}">
+				<select id="edit-direction">
+					<option value="forward">{{.Label.Forward}}</option>
+					<option value="reverse">{{.Label.Reverse}}</option>
+					<option value="both">{{.Label.Both}}</option>
+				</select>
+				<select id="edit-suspended">
+					<option value="">{{.Label.Active}}</option>
+					<option value="1">{{.Label.Suspended}}</option>
+				</select>
+				<div id="edit-history" class="history hide"></div>
 				<div class="actions">
 					<button id="edit-delete" class="fail">
 						{{.Label.Delete}}
@@ -183,7 +254,7 @@ const html = `<!DOCTYPE html>
 				</div>
 			</div>
 			<div id="update-success" class="update success hide">{{.Label.Updated}}</div>
-			<div id="delete-success" class="update success hide">{{.Label.Deleted}}</div>
+			<div id="delete-success" class="update success hide">{{.Label.Deleted}}<a id="delete-undo" class="restore" href="#">{{.Label.Restore}}</a></div>
 			<div id="error" class="update fail hide">{{.Label.Error}}</div>
 		</div>
 
@@ -195,7 +266,12 @@ const html = `<!DOCTYPE html>
 				{
 					id: {{.ID}},
 					phrase: '{{.Phrase}}',
-					explanation: '{{.Explanation}}'
+					explanation: '{{.Explanation}}',
+					alternatives: {{.Alternatives}} || [],
+					direction: {{.Direction}},
+					tags: {{.Tags}} || [],
+					suspended: {{.Suspended}},
+					leech: {{.Leech}}
 				},
 				{{end}}
 			]
@@ -212,9 +288,11 @@ const html = `<!DOCTYPE html>
 
 			var container = document.getElementById('phrases')
 			container.innerHTML = phrases.map(function(p) {
-				return '<li class="phrase">'+
-					'<span>'+p.phrase+'</span>'+
+				var c = (p.suspended ? ' suspended' : '') + (p.leech ? ' leech' : '')
+				return '<li class="phrase'+c+'">'+
+					'<span>'+[p.phrase].concat(p.alternatives).join(' | ')+'</span>'+
 					'<span>'+p.explanation+'</span>'+
+					'<span class="tags">'+p.tags.join(', ')+'</span>'+
 				'</li>'
 			}).join('')
 
@@ -226,17 +304,21 @@ const html = `<!DOCTYPE html>
 
 			var msgTimeout
 			var msgTimeoutEl
-			function msg(el) {
+			function msg(el, duration) {
 				el.classList.remove('hide')
 				msgTimeoutEl = el
 				msgTimeout = setTimeout(function() {
 					el.classList.add('hide')
-				}, 2000)
+				}, duration || 2000)
 			}
 
 			var edit = document.getElementById('edit')
 			var editPhrase = document.getElementById('edit-phrase')
+			var editAlternatives = document.getElementById('edit-alternatives')
 			var editExplanation = document.getElementById('edit-explanation')
+			var editDirection = document.getElementById('edit-direction')
+			var editTags = document.getElementById('edit-tags')
+			var editSuspended = document.getElementById('edit-suspended')
 			phrases.forEach(function(p, i) {
 				var el = items[i]
 				el.addEventListener('click', function() {
@@ -252,14 +334,48 @@ const html = `<!DOCTYPE html>
 					edit.classList.remove('hide')
 
 					editPhrase.value = p.phrase
+					editAlternatives.value = p.alternatives.join(' | ')
 					editExplanation.value = p.explanation
+					editDirection.value = p.direction
+					editTags.value = p.tags.join(', ')
+					editSuspended.value = p.suspended ? '1' : ''
 
 					el.classList.add('open')
 
 					editI = Array.prototype.indexOf.call(container.children, el)
+					loadHistory(p.id)
 				})
 			})
 
+			// Show stats and the most recent reviews of a phrase
+			var editHistory = document.getElementById('edit-history')
+			function loadHistory(id) {
+				editHistory.classList.add('hide')
+				var request = new XMLHttpRequest();
+				request.open('GET', '{{.API}}/'+id+'/history?token={{.Token}}', true);
+				request.onload = function() {
+					// Another phrase might have been opened in the meantime
+					if (request.status >= 400 || editI === undefined || phrases[editI].id !== id) {
+						return
+					}
+					var data = JSON.parse(request.responseText).data
+					if (!data.reviews.length) {
+						return
+					}
+					var s = data.stats
+					editHistory.innerHTML = '<div>'+
+						'{{.Label.Reviews}}: '+s.reviews+' · '+
+						'{{.Label.Lapses}}: '+s.lapses+' · '+
+						'{{.Label.SuccessRate}}: '+Math.round(s.successRate*100)+'% · '+
+						'{{.Label.AverageInterval}}: '+s.averageInterval.toFixed(1)+
+					'</div><ol>'+data.reviews.slice(-5).reverse().map(function(r) {
+						return '<li'+(r.failed ? ' class="failed"' : '')+'>'+new Date(r.time).toLocaleString()+' · '+r.score+'</li>'
+					}).join('')+'</ol>'
+					editHistory.classList.remove('hide')
+				};
+				request.send();
+			}
+
 			function closeEdit() {
 				if (editI === undefined) return
 				items[editI].classList.remove('open')
@@ -279,6 +395,7 @@ const html = `<!DOCTYPE html>
 				return '{{.API}}/'+phrases[editI].id+'?token={{.Token}}'
 			}
 
+			var deletedID
 			document.getElementById('delete-confirm').addEventListener('click', function() {
 				deletePrompt.classList.add('hide')
 				var request = new XMLHttpRequest();
@@ -288,13 +405,15 @@ const html = `<!DOCTYPE html>
 						request.onerror(request.responseText)
 						return
 					}
+					deletedID = phrases[editI].id
 					phrases.splice(editI, 1)
 					delete phraseStates[editI]
 					container.removeChild(items[editI])
 					items = document.getElementsByClassName('phrase')
 					handleEmpty()
 					edit.classList.add('hide')
-					msg(msgDelete)
+					// Leave time to restore the phrase
+					msg(msgDelete, 5000)
 				};
 				request.onerror = function(err) {
 						msg(msgErr)
@@ -302,9 +421,86 @@ const html = `<!DOCTYPE html>
 				request.send();
 			})
 
+			// Deleted phrases are in the trash until they are purged.
+			// Reload after restoring, since the phrase might belong anywhere in the list.
+			function restore(id) {
+				var request = new XMLHttpRequest();
+				request.open('POST', '{{.Trash}}/'+id+'/restore?token={{.Token}}', true);
+				request.onload = function() {
+					if (request.status >= 400) {
+						request.onerror(request.responseText)
+						return
+					}
+					location.reload()
+				};
+				request.onerror = function(err) {
+					msg(msgErr)
+				};
+				request.send();
+			}
+			document.getElementById('delete-undo').addEventListener('click', function(e) {
+				e.preventDefault()
+				restore(deletedID)
+			})
+
+			var trash = document.getElementById('trash')
+			document.getElementById('trash-open').addEventListener('click', function(e) {
+				e.preventDefault()
+				var request = new XMLHttpRequest();
+				request.open('GET', '{{.Trash}}?token={{.Token}}', true);
+				request.onload = function() {
+					if (request.status >= 400) {
+						request.onerror(request.responseText)
+						return
+					}
+					var deleted = JSON.parse(request.responseText).data
+					trash.innerHTML = ''
+					if (!deleted.length) {
+						var li = document.createElement('li')
+						li.className = 'empty'
+						li.textContent = '{{.Label.TrashEmpty}}'
+						trash.appendChild(li)
+					}
+					deleted.forEach(function(p) {
+						var li = document.createElement('li')
+						li.className = 'phrase'
+						;[p.phrase, p.explanation].forEach(function(text) {
+							var span = document.createElement('span')
+							span.textContent = text
+							li.appendChild(span)
+						})
+						var a = document.createElement('span')
+						a.className = 'restore'
+						a.textContent = '{{.Label.Restore}}'
+						a.addEventListener('click', function() {
+							restore(p.id)
+						})
+						li.appendChild(a)
+						trash.appendChild(li)
+					})
+					trash.classList.remove('hide')
+				};
+				request.onerror = function(err) {
+					msg(msgErr)
+				};
+				request.send();
+			})
+
 			document.getElementById('edit-save').addEventListener('click', function() {
 				var p = editPhrase.value
+				var a = editAlternatives.value.split('|').map(function(alternative) {
+					return alternative.trim()
+				}).filter(function(alternative) {
+					return alternative
+				})
 				var e = editExplanation.value
+				var d = editDirection.value
+				var t = editTags.value.split(',').map(function(tag) {
+					return tag.trim()
+				}).filter(function(tag) {
+					return tag
+				})
+				var s = !!editSuspended.value
 				var request = new XMLHttpRequest();
 				request.open('PUT', getURL(), true);
 				request.setRequestHeader('Content-Type', 'application/json; charset=UTF-8');
@@ -314,15 +510,24 @@ const html = `<!DOCTYPE html>
 						return
 					}
 					phrases[editI].phrase = p
+					phrases[editI].alternatives = a
 					phrases[editI].explanation = e
-					items[editI].children[0].innerText = p
+					phrases[editI].direction = d
+					phrases[editI].tags = t
+					phrases[editI].suspended = s
+					// Updated phrases are no leeches anymore
+					phrases[editI].leech = false
+					items[editI].classList.toggle('suspended', s)
+					items[editI].classList.remove('leech')
+					items[editI].children[0].innerText = [p].concat(a).join(' | ')
 					items[editI].children[1].innerText = e
+					items[editI].children[2].innerText = t.join(', ')
 					closeEdit()
 					msg(msgUpdate)
 				};
 				request.onerror = function(msg) {
 				};
-				request.send(JSON.stringify({ data: { phrase: p, explanation: e }}));
+				request.send(JSON.stringify({ data: { phrase: p, explanation: e, alternatives: a, direction: d, tags: t, suspended: s }}));
 			})
 
 			var search = document.getElementById('search')
@@ -330,7 +535,9 @@ const html = `<!DOCTYPE html>
 				var query = search.value.toLowerCase()
 				// Toggle phrases
 				phrases.forEach(function(p, i) {
-					var match = contains(p.phrase, query) || contains(p.explanation, query)
+					var match = contains(p.phrase, query) || contains(p.explanation, query) || p.alternatives.concat(p.tags).some(function(s) {
+						return contains(s, query)
+					})
 					if (match && phraseStates[i]) {
 						items[i].classList.remove('hide')
 						delete phraseStates[i]
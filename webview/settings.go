@@ -0,0 +1,100 @@
+package webview
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/jorinvo/slangbrain/brain"
+	"github.com/jorinvo/slangbrain/scope"
+	"github.com/jorinvo/slangbrain/translate"
+)
+
+// Settings can be used as an http.Handler to render the settings webview.
+// Always use NewSettings() for initialization.
+type Settings struct {
+	store    brain.Store
+	err      *log.Logger
+	template *template.Template
+	content  translate.Translator
+	api      string
+}
+
+// NewSettings creates a new settings webview.
+func NewSettings(s brain.Store, errLog *log.Logger, t translate.Translator, api string) http.Handler {
+	return Settings{
+		store:    s,
+		err:      errLog,
+		template: template.Must(template.New("settings").Parse(settingsHTML)),
+		content:  t,
+		api:      strings.TrimSuffix(api, "/") + "/settings",
+	}
+}
+
+// ServeHTTP handles a HTTP request by rendering the settings HTML page.
+// Requires a token in the path to authenticate a user.
+func (view Settings) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id, token, ok := authorize(view.store, view.err, w, r)
+	if !ok {
+		return
+	}
+	settings, err := view.store.GetSettings(id)
+	if err != nil {
+		view.err.Println(err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	forecast, err := view.store.Forecast(id, brain.ForecastDays)
+	if err != nil {
+		view.err.Println(err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	u := scope.Get(id, view.store, view.content, view.err, nil)
+	data := struct {
+		Settings brain.Settings
+		Forecast []forecastBar
+		Label    translate.Web
+		API      string
+		Token    string
+	}{settings, forecastBars(u.Msg, forecast), u.Web, view.api, token}
+	if err := view.template.Execute(w, data); err != nil {
+		view.err.Printf("failed to render template: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// forecastBar is a day of the forecast chart.
+// Heights are in percent of the chart height.
+type forecastBar struct {
+	Label     string
+	Total     int
+	DueHeight int
+	NewHeight int
+}
+
+// Scale the days of a forecast to bars relative to the busiest day.
+// Leaves space for the labels above and below the bars.
+func forecastBars(msg translate.Msg, forecast []brain.ForecastDay) []forecastBar {
+	max := 1
+	for _, d := range forecast {
+		if d.Due+d.New > max {
+			max = d.Due + d.New
+		}
+	}
+	bars := make([]forecastBar, len(forecast))
+	for i, d := range forecast {
+		label := d.Day.Format(msg.DateFormat)
+		if i == 0 {
+			label = msg.Today
+		}
+		bars[i] = forecastBar{
+			Label:     label,
+			Total:     d.Due + d.New,
+			DueHeight: d.Due * 70 / max,
+			NewHeight: d.New * 70 / max,
+		}
+	}
+	return bars
+}
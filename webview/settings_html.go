@@ -0,0 +1,200 @@
+package webview
+
+const settingsHTML = `<!DOCTYPE html>
+<html>
+	<head>
+		<title>{{.Label.Settings}}</title>
+
+		<meta charset="utf-8">
+		<meta name="viewport" content="width=device-width,minimum-scale=1.0,maximum-scale=1.0">
+
+		<style>
+			body,
+			html {
+				padding: 0;
+				margin: 0;
+				background: white;
+			}
+			body {
+				font-family: Helvetica Neue, Helvetica, Arial, sans-serif;
+			}
+			.hide {
+				display: none;
+			}
+			label {
+				display: block;
+				margin: 3% 3% 0;
+				font-size: 86%;
+				color: #939393;
+			}
+			input,
+			select {
+				width: 94%;
+				padding: 2%;
+				margin: 1% 3% 3%;
+				font-size: 100%;
+				box-sizing: border-box;
+				border: 1px solid #dedede;
+				border-radius: 5px;
+			}
+			select {
+				background: white;
+			}
+			input:focus,
+			select:focus {
+				outline: none;
+				border: 1px solid #939393;
+			}
+			.half {
+				width: 44.5%;
+				display: inline-block;
+			}
+			.half input {
+				width: 100%;
+				margin: 1% 0 3%;
+			}
+			.forecast {
+				display: flex;
+				height: 120px;
+				margin: 1% 3% 3%;
+			}
+			.day {
+				flex: 1;
+				display: flex;
+				flex-direction: column;
+				justify-content: flex-end;
+				text-align: center;
+				font-size: 70%;
+				color: #939393;
+			}
+			.bar {
+				margin: 0 15%;
+				background: #ff207e;
+			}
+			.bar.new {
+				background: #ffb3d3;
+			}
+			button {
+				border: 1px solid rgba(0, 0, 0, 0);
+				width: 94%;
+				margin: 3%;
+				padding: 2.5% 0;
+				cursor: pointer;
+				background: #ff207e;
+				color: white;
+				font-family: monospace;
+				font-size: 105%;
+				border-radius: 5px;
+				-webkit-tap-highlight-color: rgba(0, 0, 0, 0);
+			}
+			button:hover,
+			button:focus {
+				outline: none;
+				border: 1px solid #ff207e;
+				background: white;
+				color: #ff207e;
+			}
+			.update {
+				position: fixed;
+				-webkit-backface-visibility: hidden;
+				bottom: 0;
+				width: 100%;
+				text-align: center;
+				padding: 6%;
+				box-sizing: border-box;
+				background: #ff207e;
+				color: white;
+				font-size: 105%;
+			}
+		</style>
+	</head>
+	<body>
+
+		<label for="new-phrases">{{.Label.NewPhrases}}</label>
+		<input id="new-phrases" type="number" min="0" value="{{.Settings.NewPhrases}}">
+		<label for="interval-multiplier">{{.Label.IntervalMultiplier}}</label>
+		<input id="interval-multiplier" type="number" min="0.1" max="10" step="0.1" value="{{.Settings.IntervalMultiplier}}">
+		<label for="notify-min-count">{{.Label.NotifyMinCount}}</label>
+		<input id="notify-min-count" type="number" min="1" value="{{.Settings.NotifyMinCount}}">
+		<label class="half" for="quiet-start">
+			{{.Label.QuietStart}}
+			<input id="quiet-start" type="number" min="0" max="23" value="{{.Settings.QuietStart}}">
+		</label><label class="half" for="quiet-end">
+			{{.Label.QuietEnd}}
+			<input id="quiet-end" type="number" min="0" max="23" value="{{.Settings.QuietEnd}}">
+		</label>
+		<label for="multiple-choice">{{.Label.StudyBy}}</label>
+		<select id="multiple-choice">
+			<option value="">{{.Label.TypeAnswers}}</option>
+			<option value="1" {{if .Settings.MultipleChoice}}selected{{end}}>{{.Label.MultipleChoice}}</option>
+		</select>
+		<label for="daily-studies">{{.Label.DailyStudies}}</label>
+		<input id="daily-studies" type="number" min="0" value="{{.Settings.DailyStudies}}">
+		<label for="daily-adds">{{.Label.DailyAdds}}</label>
+		<input id="daily-adds" type="number" min="0" value="{{.Settings.DailyAdds}}">
+		<label for="scheduler">{{.Label.Scheduler}}</label>
+		<select id="scheduler">
+			<option value="table">{{.Label.SchedulerTable}}</option>
+			<option value="sm2" {{if eq .Settings.Scheduler "sm2"}}selected{{end}}>{{.Label.SchedulerSM2}}</option>
+			<option value="fsrs" {{if eq .Settings.Scheduler "fsrs"}}selected{{end}}>{{.Label.SchedulerFSRS}}</option>
+		</select>
+		<label>{{.Label.Forecast}}</label>
+		<div class="forecast">
+			{{range .Forecast}}
+			<div class="day">
+				{{.Total}}
+				<div class="bar new" style="height: {{.NewHeight}}%"></div>
+				<div class="bar" style="height: {{.DueHeight}}%"></div>
+				{{.Label}}
+			</div>
+			{{end}}
+		</div>
+		<button id="save">{{.Label.Save}}</button>
+
+		<div id="update-success" class="update hide">{{.Label.SettingsUpdated}}</div>
+		<div id="error" class="update hide">{{.Label.Error}}</div>
+
+		<script>
+			var msgTimeout
+			function msg(el) {
+				clearTimeout(msgTimeout)
+				el.classList.remove('hide')
+				msgTimeout = setTimeout(function() {
+					el.classList.add('hide')
+				}, 2000)
+			}
+
+			function value(id) {
+				return Number(document.getElementById(id).value)
+			}
+
+			document.getElementById('save').addEventListener('click', function() {
+				var request = new XMLHttpRequest();
+				request.open('PUT', '{{.API}}?token={{.Token}}', true);
+				request.setRequestHeader('Content-Type', 'application/json; charset=UTF-8');
+				request.onload = function() {
+					if (request.status >= 400) {
+						request.onerror(request.responseText)
+						return
+					}
+					msg(document.getElementById('update-success'))
+				};
+				request.onerror = function(err) {
+					msg(document.getElementById('error'))
+				};
+				request.send(JSON.stringify({ data: {
+					newPhrases: value('new-phrases'),
+					intervalMultiplier: value('interval-multiplier'),
+					notifyMinCount: value('notify-min-count'),
+					quietStart: value('quiet-start'),
+					quietEnd: value('quiet-end'),
+					multipleChoice: document.getElementById('multiple-choice').value === '1',
+					dailyStudies: value('daily-studies'),
+					dailyAdds: value('daily-adds'),
+					scheduler: document.getElementById('scheduler').value
+				}}));
+			})
+		</script>
+	</body>
+</html>
+`
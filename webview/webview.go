@@ -19,6 +19,7 @@ type Webview struct {
 	template *template.Template
 	content  translate.Translator
 	api      string
+	trash    string
 }
 
 // New creates a new Webview.
@@ -29,6 +30,7 @@ func New(s brain.Store, errLog *log.Logger, t translate.Translator, api string)
 		template: template.Must(template.New("manage").Parse(html)),
 		content:  t,
 		api:      strings.TrimSuffix(api, "/") + "/phrases",
+		trash:    strings.TrimSuffix(api, "/") + "/trash",
 	}
 }
 
@@ -36,47 +38,74 @@ func New(s brain.Store, errLog *log.Logger, t translate.Translator, api string)
 // Requires a token in the path to authenticate a user.
 // ALso restricts IFrame usage to only Facebook domains.
 func (view Webview) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+	id, token, ok := authorize(view.store, view.err, w, r)
+	if !ok {
 		return
 	}
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	// Allow rendering inline on web
-	if ref := validReferer(r.Referer()); ref == "" {
-		view.err.Printf("Denied X-Frame for unknown page '%s'\n", ref)
-	} else {
-		w.Header().Del("X-Frame-Options")
-	}
-
-	// Validate token
-	token := r.URL.Path
-	id, err := view.store.LookupToken(token)
+	// Get phrases, get localized content and render template.
+	// The same query parameters as for the API can be used to show a page of phrases.
+	values := r.URL.Query()
+	q, err := brain.ParsePhraseQuery(values)
 	if err != nil {
-		view.err.Printf("failed looking up token '%s': %v", token, err)
-		http.Error(w, "invalid token", http.StatusUnauthorized)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	phrases, next, err := view.store.QueryPhrases(id, q)
+	if err == brain.ErrInvalidCursor {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
 		return
 	}
-	// Get phrases, get localized content and render template
-	phrases, err := view.store.GetAllPhrases(id)
 	if err != nil {
 		view.err.Println(err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
+	if next != "" {
+		values.Set("cursor", next)
+		next = "?" + values.Encode()
+	}
 	u := scope.Get(id, view.store, view.content, view.err, nil)
 	data := struct {
 		Phrases []brain.IDPhrase
 		Label   translate.Web
 		API     string
+		Trash   string
 		Token   string
-	}{phrases, u.Web, view.api, token}
+		Next    string
+	}{phrases, u.Web, view.api, view.trash, token, next}
 	if err := view.template.Execute(w, data); err != nil {
 		view.err.Printf("failed to render template: %v", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
 	}
 }
 
+// Check method, set headers and validate the token in the path.
+// Responds with an error if the request is invalid.
+func authorize(store brain.Store, errLog *log.Logger, w http.ResponseWriter, r *http.Request) (int64, string, bool) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "invalid method", http.StatusMethodNotAllowed)
+		return 0, "", false
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	// Allow rendering inline on web
+	if ref := validReferer(r.Referer()); ref == "" {
+		errLog.Printf("Denied X-Frame for unknown page '%s'\n", ref)
+	} else {
+		w.Header().Del("X-Frame-Options")
+	}
+
+	// Validate token
+	token := r.URL.Path
+	id, err := store.LookupToken(token)
+	if err != nil {
+		errLog.Printf("failed looking up token '%s': %v", token, err)
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return id, token, false
+	}
+	return id, token, true
+}
+
 func validReferer(ref string) string {
 	allowFrom := []string{
 		"https://www.messenger.com/",